@@ -1,29 +1,143 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
-	
+	"net/url"
+	"strings"
+	"time"
+
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite3"
+	// DriverMemory - tanpa database, semua data di RAM (repositories/memory)
+	DriverMemory = "memory"
 )
 
-func InitDB(connectionString string) (*sql.DB, error)   {
+// ParseConn - tentukan driver dari scheme DB_CONN.
+// postgres://... atau postgresql://... -> Postgres
+// sqlite://kasir.db atau sqlite:///var/lib/kasir.db -> SQLite (file tunggal)
+// memory:// -> repository in-memory, tidak buka koneksi database
+// Tanpa scheme dianggap format key=value milik lib/pq
+func ParseConn(connectionString string) (driver string, dsn string) {
+	if strings.HasPrefix(connectionString, "memory://") {
+		return DriverMemory, ""
+	}
+	if rest, ok := strings.CutPrefix(connectionString, "sqlite://"); ok {
+		path, query, _ := strings.Cut(rest, "?")
+		params, _ := url.ParseQuery(query)
+		// FK di SQLite default-nya mati, busy_timeout biar tidak langsung "database is locked"
+		if params.Get("_foreign_keys") == "" && params.Get("_fk") == "" {
+			params.Set("_foreign_keys", "on")
+		}
+		if params.Get("_busy_timeout") == "" {
+			params.Set("_busy_timeout", "5000")
+		}
+		return DriverSQLite, path + "?" + params.Encode()
+	}
+
+	return DriverPostgres, connectionString
+}
+
+// DriverName - nama driver untuk DB_CONN, dipakai migration dan query yang beda dialek
+func DriverName(connectionString string) string {
+	driver, _ := ParseConn(connectionString)
+	return driver
+}
+
+// Options - pengaturan connection pool dan retry koneksi awal
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ConnectRetries - berapa kali coba ulang kalau Ping pertama gagal
+	// (misal API start duluan sebelum database siap di docker-compose)
+	ConnectRetries int
+	// ConnectBackoff - jeda sebelum retry pertama, dikali 2 tiap percobaan
+	// sampai maksimal ConnectMaxBackoff
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxOpenConns:      25,
+		MaxIdleConns:      5,
+		ConnMaxLifetime:   30 * time.Minute,
+		ConnMaxIdleTime:   5 * time.Minute,
+		ConnectRetries:    5,
+		ConnectBackoff:    time.Second,
+		ConnectMaxBackoff: 30 * time.Second,
+	}
+}
+
+func InitDB(connectionString string, opts Options) (*sql.DB, error) {
+	driver, dsn := ParseConn(connectionString)
+
 	// Open Database
-	db, err := sql.Open("postgres", connectionString)
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	// Test Connection
-	err = db.Ping()
+	// Test Connection, retry dengan exponential backoff
+	err = pingWithRetry(db, opts)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	if driver == DriverSQLite {
+		// SQLite cuma boleh satu writer, satu koneksi menghindari "database is locked"
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(opts.MaxOpenConns)
+		db.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
 
-	log.Println("Database connected successf")
+	log.Println("Database connected successfully using", driver)
 	return db, nil
-}
\ No newline at end of file
+}
+
+func pingWithRetry(db *sql.DB, opts Options) error {
+	attempts := opts.ConnectRetries + 1
+	backoff := opts.ConnectBackoff
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Database siap setelah %d percobaan", attempt)
+			}
+			return nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		log.Printf("Database belum siap (percobaan %d/%d): %v, coba lagi dalam %s", attempt, attempts, err, backoff)
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if opts.ConnectMaxBackoff > 0 && backoff > opts.ConnectMaxBackoff {
+			backoff = opts.ConnectMaxBackoff
+		}
+	}
+
+	return fmt.Errorf("database tidak bisa dihubungi setelah %d percobaan: %w", attempts, err)
+}
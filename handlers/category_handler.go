@@ -10,14 +10,15 @@ import (
 )
 
 type CategoryHandler struct {
-	service *services.CategoryService
+	service       *services.CategoryService
+	produkService *services.ProdukService
 }
 
-func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
-	return &CategoryHandler{service: service}
+func NewCategoryHandler(service *services.CategoryService, produkService *services.ProdukService) *CategoryHandler {
+	return &CategoryHandler{service: service, produkService: produkService}
 }
 
-// HandleCategory - GET /api/produk
+// HandleCategory - GET/POST /api/category
 func (h *CategoryHandler) HandleCategory(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -30,9 +31,10 @@ func (h *CategoryHandler) HandleCategory(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	category, err := h.service.GetAll()
+	// ?include_archived=true ikut tampilkan category yang diarsipkan
+	category, err := h.service.GetAll(r.Context(), r.URL.Query().Get("include_archived") == "true")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -48,9 +50,9 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Create(&category)
+	err = h.service.Create(r.Context(), &category)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -59,13 +61,34 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(category)
 }
 
-// HandleCategoryByID - GET/PUT/DELETE /api/produk/{id}
+// HandleCategoryByID - GET/PUT/DELETE /api/category/{id},
+// GET /api/category/{id}/produk dan POST /api/category/{id}/restore
 func (h *CategoryHandler) HandleCategoryByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/restore") {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Restore(w, r)
+		return
+	}
+
+	if strings.HasSuffix(r.URL.Path, "/produk") {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetProduk(w, r)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		h.GetByID(w, r)
 	case http.MethodPut:
 		h.Update(w, r)
+	case http.MethodPatch:
+		h.Patch(w, r)
 	case http.MethodDelete:
 		h.Delete(w, r)
 	default:
@@ -73,18 +96,18 @@ func (h *CategoryHandler) HandleCategoryByID(w http.ResponseWriter, r *http.Requ
 	}
 }
 
-// GetByID - GET /api/produk/{id}
+// GetByID - GET /api/category/{id}
 func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		http.Error(w, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
-	category, err := h.service.GetByID(id)
+	category, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
 		return
 	}
 
@@ -93,7 +116,7 @@ func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		http.Error(w, "Invalid category ID", http.StatusBadRequest)
@@ -108,28 +131,71 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	category.ID = id
-	err = h.service.Update(&category)
+	err = h.service.Update(r.Context(), &category)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// Patch - PATCH /api/category/{id} dengan JSON Merge Patch
+func (h *CategoryHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	patch, err := decodeMergePatch(r, "name")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	current, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	var category models.Category
+	if err := applyMergePatch(current, patch, &category); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if category.Name == "" {
+		http.Error(w, "name tidak boleh kosong", http.StatusBadRequest)
+		return
+	}
+
+	category.ID = id
+	category.DeletedAt = current.DeletedAt
+	err = h.service.Update(r.Context(), &category)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(category)
 }
 
-// Delete - DELETE /api/produk/{id}
+// Delete - DELETE /api/category/{id}
 func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		http.Error(w, "Invalid category ID", http.StatusBadRequest)
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -137,4 +203,63 @@ func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "Category deleted successfully",
 	})
-}
\ No newline at end of file
+}
+
+// Restore - POST /api/category/{id}/restore
+func (h *CategoryHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
+	idStr = strings.TrimSuffix(idStr, "/restore")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Restore(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	category, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// GetProduk - GET /api/category/{id}/produk
+func (h *CategoryHandler) GetProduk(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
+	idStr = strings.TrimSuffix(idStr, "/produk")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.service.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	filter, err := produkFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.CategoryID = id
+
+	produk, err := h.produkService.List(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(produk)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"kasirApi/repositories"
+	"kasirApi/services"
+	"kasirApi/storage"
+)
+
+// errorStatus - status HTTP untuk error dari service. Timeout dari context
+// jadi 504, konflik versi jadi 412, cursor rusak jadi 400, SKU/barcode dobel
+// restore data yang tidak diarsipkan dan stok minus jadi 409, gambar kebesaran 413,
+// format gambar salah 415, produk, category dan file storage tidak ada 404,
+// selain itu pakai status bawaan handler.
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, repositories.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, repositories.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, repositories.ErrDuplicateCode), errors.Is(err, repositories.ErrNotArchived),
+		errors.Is(err, repositories.ErrNegativeStock):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrUnsupportedImage):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, repositories.ErrProdukNotFound), errors.Is(err, repositories.ErrCategoryNotFound),
+		errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrInvalidKey):
+		return http.StatusBadRequest
+	}
+	return fallback
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"kasirApi/migrations"
+)
+
+type HealthHandler struct {
+	db      *sql.DB // nil kalau pakai repository in-memory
+	replica *sql.DB // nil kalau DB_REPLICA_CONN tidak diisi
+	driver  string
+}
+
+func NewHealthHandler(db, replica *sql.DB, driver string) *HealthHandler {
+	return &HealthHandler{db: db, replica: replica, driver: driver}
+}
+
+type healthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// database
+	OpenConnections *int   `json:"open_connections,omitempty"`
+	InUse           *int   `json:"in_use,omitempty"`
+	Idle            *int   `json:"idle,omitempty"`
+	WaitCount       *int64 `json:"wait_count,omitempty"`
+	WaitDurationMs  *int64 `json:"wait_duration_ms,omitempty"`
+
+	// migrations
+	Pending *int `json:"pending,omitempty"`
+}
+
+// Live - GET /health/live
+// Cukup memastikan proses masih jalan, tidak cek dependency
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status": "OK",
+	})
+}
+
+// Ready - GET /health/ready
+// Cek database dan migration, 503 kalau ada yang bermasalah supaya
+// load balancer berhenti mengirim traffic ke instance ini. Replica hanya
+// dilaporkan di checks: replica mati tidak boleh mengeluarkan semua instance
+// dari load balancer, checkout dan tulis tetap jalan di primary
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	checks := map[string]healthCheck{}
+	healthy := true
+
+	if h.db == nil {
+		checks["database"] = healthCheck{Status: "SKIPPED"}
+	} else {
+		dbCheck := checkDatabase(ctx, h.db)
+		migrationCheck := h.checkMigrations(ctx)
+		checks["database"] = dbCheck
+		checks["migrations"] = migrationCheck
+		healthy = dbCheck.Status == "OK" && migrationCheck.Status == "OK"
+	}
+
+	if h.replica != nil {
+		replicaCheck := checkDatabase(ctx, h.replica)
+		checks["replica"] = replicaCheck
+	}
+
+	status := "OK"
+	code := http.StatusOK
+	if !healthy {
+		status = "DEGRADED"
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+func checkDatabase(ctx context.Context, db *sql.DB) healthCheck {
+	stats := db.Stats()
+	waitMs := stats.WaitDuration.Milliseconds()
+	check := healthCheck{
+		Status:          "OK",
+		OpenConnections: &stats.OpenConnections,
+		InUse:           &stats.InUse,
+		Idle:            &stats.Idle,
+		WaitCount:       &stats.WaitCount,
+		WaitDurationMs:  &waitMs,
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		check.Status = "DOWN"
+		check.Error = err.Error()
+	}
+
+	return check
+}
+
+func (h *HealthHandler) checkMigrations(ctx context.Context) healthCheck {
+	pending, err := migrations.Pending(ctx, h.db, h.driver)
+	if err != nil {
+		return healthCheck{Status: "UNKNOWN", Error: err.Error()}
+	}
+
+	check := healthCheck{Status: "OK", Pending: &pending}
+	if pending > 0 {
+		check.Status = "PENDING"
+	}
+
+	return check
+}
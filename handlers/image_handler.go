@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"io"
+	"kasirApi/storage"
+	"net/http"
+	"strings"
+)
+
+// ImageHandler - sajikan file dari storage di /images/{key}
+type ImageHandler struct {
+	storage storage.Storage
+}
+
+func NewImageHandler(storage storage.Storage) *ImageHandler {
+	return &ImageHandler{storage: storage}
+}
+
+// ServeHTTP - GET /images/produk/1/ab12cd.jpg
+func (h *ImageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/images/")
+	body, info, err := h.storage.Get(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	defer body.Close()
+
+	// Nama file berasal dari hash isinya, jadi isi di URL yang sama tidak pernah berubah
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+key+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// ServeContent menangani If-None-Match, If-Modified-Since dan Range
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", info.ModTime, seeker)
+		return
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" && match == w.Header().Get("ETag") {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	io.Copy(w, body)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// mergePatch - JSON Merge Patch (RFC 7396): field di patch menimpa target,
+// null menghapus field, object digabung rekursif, selain object diganti utuh
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+
+	return targetObj
+}
+
+// applyMergePatch - terapkan patch ke bentuk JSON current lalu decode ke out.
+// Field yang di-null-kan jadi zero value di out
+func applyMergePatch(current interface{}, patch map[string]interface{}, out interface{}) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	var target interface{}
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	if err := decoder.Decode(&target); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, out); err != nil {
+		return fmt.Errorf("Invalid request body: %w", err)
+	}
+	return nil
+}
+
+// decodeMergePatch - body PATCH harus object JSON dan hanya berisi field yang
+// boleh diubah. Content-Type application/merge-patch+json atau application/json
+func decodeMergePatch(r *http.Request, allowed ...string) (map[string]interface{}, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json-patch+json") {
+		return nil, errors.New("JSON Patch (RFC 6902) tidak didukung, kirim JSON Merge Patch (application/merge-patch+json)")
+	}
+
+	var patch map[string]interface{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil || patch == nil {
+		return nil, errors.New("Invalid request body, harus object JSON")
+	}
+
+	for key := range patch {
+		if !slices.Contains(allowed, key) {
+			return nil, fmt.Errorf("field %s tidak bisa diubah lewat PATCH, pilih: %s", key, strings.Join(allowed, ", "))
+		}
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasirApi/models"
+	"net/http"
+)
+
+// bundleRequest - body PUT /api/produk/{id}/bundle
+type bundleRequest struct {
+	Items []models.BundleItem `json:"items"`
+}
+
+// HandleBundle - /api/produk/{id}/bundle: GET komponen, PUT ganti komponen,
+// DELETE jadikan produk biasa lagi
+func (h *ProdukHandler) HandleBundle(w http.ResponseWriter, r *http.Request, produkID int) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetBundle(w, r, produkID)
+	case http.MethodPut:
+		var req bundleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		h.SetBundle(w, r, produkID, req.Items)
+	case http.MethodDelete:
+		h.SetBundle(w, r, produkID, nil)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetBundle - GET /api/produk/{id}/bundle
+func (h *ProdukHandler) GetBundle(w http.ResponseWriter, r *http.Request, produkID int) {
+	items, err := h.service.GetBundle(r.Context(), produkID)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bundleRequest{Items: items})
+}
+
+// SetBundle - PUT / DELETE /api/produk/{id}/bundle, balikannya produk paket
+func (h *ProdukHandler) SetBundle(w http.ResponseWriter, r *http.Request, produkID int, items []models.BundleItem) {
+	produk, err := h.service.SetBundle(r.Context(), produkID, items)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(produk))
+	json.NewEncoder(w).Encode(produk)
+}
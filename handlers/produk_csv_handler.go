@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"kasirApi/services"
+	"net/http"
+	"strings"
+)
+
+// maxImportSize - batas ukuran file CSV import
+const maxImportSize = 10 << 20
+
+type ProdukCSVHandler struct {
+	service *services.ProdukCSVService
+}
+
+func NewProdukCSVHandler(service *services.ProdukCSVService) *ProdukCSVHandler {
+	return &ProdukCSVHandler{service: service}
+}
+
+// Import - POST /api/produk/import?dry_run=true
+// Body CSV langsung (Content-Type: text/csv) atau multipart dengan field "file".
+// 200 kalau semua baris tersimpan (atau lolos validasi saat dry-run),
+// 422 kalau ada baris yang gagal dan tidak ada yang disimpan
+func (h *ProdukCSVHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "File CSV wajib dikirim di field file", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.service.Import(r.Context(), body, r.URL.Query().Get("dry_run") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(result.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// Export - GET /api/produk/export?format=csv&include_archived=true
+func (h *ProdukCSVHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+		http.Error(w, "Format tidak didukung, pilih: csv", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="produk.csv"`)
+	err := h.service.Export(r.Context(), w, r.URL.Query().Get("include_archived") == "true")
+	if err != nil {
+		// Query produk gagal sebelum ada baris CSV yang ditulis, jadi status masih bisa diganti
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+	}
+}
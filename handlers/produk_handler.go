@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"kasirApi/models"
+	"kasirApi/repositories"
 	"kasirApi/services"
 	"net/http"
 	"strconv"
@@ -30,10 +32,15 @@ func (h *ProdukHandler) HandleProduk(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ProdukHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
-	produk, err := h.service.GetAll(name)
+	filter, err := produkFilterFromQuery(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	produk, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -49,24 +56,83 @@ func (h *ProdukHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Create(&produk)
+	err = h.service.Create(r.Context(), &produk)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(&produk))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(produk)
 }
 
-// HandleProdukByID - GET/PUT/DELETE /api/produk/{id}
+// HandleProdukByID - GET/PUT/DELETE /api/produk/{id},
+// GET /api/produk/barcode/{code} dan sub-resource /api/produk/{id}/...
 func (h *ProdukHandler) HandleProdukByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/api/produk/barcode/") {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByBarcode(w, r)
+		return
+	}
+
+	if id, sub, ok := produkSubresource(r); ok {
+		switch sub[0] {
+		case "variants":
+			h.HandleVariants(w, r, id, sub[1:])
+		case "bundle":
+			if len(sub) > 1 {
+				http.NotFound(w, r)
+				return
+			}
+			h.HandleBundle(w, r, id)
+		case "restore":
+			if r.Method != http.MethodPost || len(sub) > 1 {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.Restore(w, r, id)
+		case "price-history":
+			if r.Method != http.MethodGet || len(sub) > 1 {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.GetPriceHistory(w, r, id)
+		case "stock-movements":
+			if r.Method != http.MethodGet || len(sub) > 1 {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.GetStockMovements(w, r, id)
+		case "stock-adjustments":
+			if r.Method != http.MethodPost || len(sub) > 1 {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			h.CreateStockAdjustment(w, r, id)
+		case "image":
+			if len(sub) > 1 {
+				http.NotFound(w, r)
+				return
+			}
+			h.HandleImage(w, r, id)
+		default:
+			http.NotFound(w, r)
+		}
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		h.GetByID(w, r)
 	case http.MethodPut:
 		h.Update(w, r)
+	case http.MethodPatch:
+		h.Patch(w, r)
 	case http.MethodDelete:
 		h.Delete(w, r)
 	default:
@@ -83,13 +149,33 @@ func (h *ProdukHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	produk, err := h.service.GetByID(id)
+	produk, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(produk))
+	json.NewEncoder(w).Encode(produk)
+}
+
+// GetByBarcode - GET /api/produk/barcode/{code}
+func (h *ProdukHandler) GetByBarcode(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimPrefix(r.URL.Path, "/api/produk/barcode/")
+	if code == "" {
+		http.Error(w, "Invalid barcode", http.StatusBadRequest)
+		return
+	}
+
+	produk, err := h.service.GetByBarcode(r.Context(), code)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(produk))
 	json.NewEncoder(w).Encode(produk)
 }
 
@@ -108,14 +194,85 @@ func (h *ProdukHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Versi hanya dari If-Match, tanpa header update tetap jalan seperti biasa
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
 	produk.ID = id
-	err = h.service.Update(&produk)
+	produk.Version = version
+	err = h.service.Update(r.Context(), &produk)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(&produk))
+	json.NewEncoder(w).Encode(produk)
+}
+
+// produkPatchFields - field yang boleh dikirim lewat PATCH. Stok sengaja
+// tidak termasuk, perubahan stok harus lewat penyesuaian stok yang tercatat
+var produkPatchFields = []string{"name", "price", "cost_price", "reorder_level", "unit", "category_id", "sku", "barcodes"}
+
+// Patch - PATCH /api/produk/{id} dengan JSON Merge Patch, hanya field yang
+// dikirim yang berubah. null menghapus sku/barcodes/category_id
+func (h *ProdukHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid produk ID", http.StatusBadRequest)
+		return
+	}
+
+	patch, err := decodeMergePatch(r, produkPatchFields...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
+	produk, err := h.service.Patch(r.Context(), id, version, func(produk *models.Produk) error {
+		var patched models.Produk
+		if err := applyMergePatch(produk, patch, &patched); err != nil {
+			return err
+		}
+		if patched.Name == "" {
+			return errors.New("name tidak boleh kosong")
+		}
+
+		produk.Name = patched.Name
+		produk.Price = patched.Price
+		produk.CostPrice = patched.CostPrice
+		produk.ReorderLevel = patched.ReorderLevel
+		produk.Unit = patched.Unit
+		if _, ok := patch["unit"]; ok && produk.Unit == "" {
+			produk.Unit = models.DefaultUnit
+		}
+		produk.CategoryID = patched.CategoryID
+		produk.SKU = patched.SKU
+		produk.Barcodes = patched.Barcodes
+		// Barcodes nil di Update artinya tidak diubah, null di patch artinya kosongkan
+		if _, ok := patch["barcodes"]; ok && produk.Barcodes == nil {
+			produk.Barcodes = []string{}
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(produk))
 	json.NewEncoder(w).Encode(produk)
 }
 
@@ -128,9 +285,9 @@ func (h *ProdukHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -140,3 +297,200 @@ func (h *ProdukHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetPriceHistory - GET /api/produk/{id}/price-history
+func (h *ProdukHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request, id int) {
+	history, err := h.service.GetPriceHistory(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// produkSubresource - pecah /api/produk/{id}/{sub}/... jadi id dan segmen
+// setelahnya. ok false kalau path hanya /api/produk/{id}
+func produkSubresource(r *http.Request) (int, []string, bool) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/produk/"), "/")
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 {
+		return 0, nil, false
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, nil, false
+	}
+	return id, parts[1:], true
+}
+
+// Restore - POST /api/produk/{id}/restore
+func (h *ProdukHandler) Restore(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.Restore(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	produk, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(produk))
+	json.NewEncoder(w).Encode(produk)
+}
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search - GET /api/produk/search?q=indomi&limit=20
+func (h *ProdukHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Parameter q wajib diisi", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r.URL.Query().Get("limit"), defaultSearchLimit)
+	if err != nil || limit < 1 {
+		http.Error(w, "Invalid limit", http.StatusBadRequest)
+		return
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	results, err := h.service.Search(r.Context(), query, limit)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// LowStock - GET /api/produk/low-stock, produk yang stoknya sudah di bawah
+// reorder_level. Menerima filter dan paging yang sama dengan GET /api/produk
+func (h *ProdukHandler) LowStock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := produkFilterFromQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.LowStock = true
+
+	produk, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(produk)
+}
+
+// produkETag - ETag dari versi produk, contoh: "3"
+func produkETag(produk *models.Produk) string {
+	return `"` + strconv.Itoa(produk.Version) + `"`
+}
+
+// ifMatchVersion - versi dari header If-Match ("3" atau W/"3").
+// Return 0 kalau header kosong atau "*" (tidak ada pengecekan versi)
+func ifMatchVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	tag = strings.Trim(tag, `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, errors.New("If-Match tidak cocok dengan versi produk")
+	}
+
+	return version, nil
+}
+
+const (
+	defaultProdukLimit = 50
+	maxProdukLimit     = 200
+)
+
+// produkFilterFromQuery - baca filter list produk dari query string:
+// ?name=&category_id=&min_price=&max_price=&in_stock=true&low_stock=true&include_archived=true
+// &sort=price (atau -price untuk descending)&limit=&offset=&cursor=&expand=category
+func produkFilterFromQuery(r *http.Request) (models.ProdukFilter, error) {
+	query := r.URL.Query()
+	filter := models.ProdukFilter{
+		Name:            query.Get("name"),
+		InStock:         query.Get("in_stock") == "true",
+		LowStock:        query.Get("low_stock") == "true",
+		IncludeArchived: query.Get("include_archived") == "true",
+		ExpandCategory:  query.Get("expand") == "category",
+		Limit:           defaultProdukLimit,
+		Cursor:          query.Get("cursor"),
+	}
+
+	var err error
+	if filter.CategoryID, err = queryInt(query.Get("category_id"), 0); err != nil {
+		return filter, errors.New("Invalid category_id")
+	}
+	if filter.Limit, err = queryInt(query.Get("limit"), defaultProdukLimit); err != nil || filter.Limit < 1 {
+		return filter, errors.New("Invalid limit")
+	}
+	if filter.Limit > maxProdukLimit {
+		filter.Limit = maxProdukLimit
+	}
+	if filter.Offset, err = queryInt(query.Get("offset"), 0); err != nil || filter.Offset < 0 {
+		return filter, errors.New("Invalid offset")
+	}
+
+	if v := query.Get("min_price"); v != "" {
+		price, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, errors.New("Invalid min_price")
+		}
+		filter.MinPrice = &price
+	}
+	if v := query.Get("max_price"); v != "" {
+		price, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, errors.New("Invalid max_price")
+		}
+		filter.MaxPrice = &price
+	}
+
+	sort := query.Get("sort")
+	if strings.HasPrefix(sort, "-") {
+		filter.Desc = true
+		sort = strings.TrimPrefix(sort, "-")
+	}
+	if !repositories.ValidProdukSort(sort) {
+		return filter, errors.New("Invalid sort, pilih: id, name, price, stock")
+	}
+	filter.Sort = sort
+
+	return filter, nil
+}
+
+func queryInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
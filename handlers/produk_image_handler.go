@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"kasirApi/services"
+	"net/http"
+	"strings"
+)
+
+// HandleImage - /api/produk/{id}/image
+func (h *ProdukHandler) HandleImage(w http.ResponseWriter, r *http.Request, id int) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetImage(w, r, id)
+	case http.MethodPost, http.MethodPut:
+		h.UploadImage(w, r, id)
+	case http.MethodDelete:
+		h.DeleteImage(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetImage - GET /api/produk/{id}/image?size=thumbnail, redirect ke URL gambar
+func (h *ProdukHandler) GetImage(w http.ResponseWriter, r *http.Request, id int) {
+	produk, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+	if produk.ImageURL == "" {
+		http.Error(w, "produk belum punya gambar", http.StatusNotFound)
+		return
+	}
+
+	target := produk.ImageURL
+	if r.URL.Query().Get("size") == "thumbnail" {
+		target = produk.ThumbnailURL
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// UploadImage - POST /api/produk/{id}/image, multipart dengan field "image"
+func (h *ProdukHandler) UploadImage(w http.ResponseWriter, r *http.Request, id int) {
+	// Sisa 1 MB untuk boundary dan field multipart lain
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxImageSize+1<<20)
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, services.ErrImageTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Gambar wajib dikirim di field image (multipart/form-data)", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// Content-Type dari client hanya dicek kasar, isi file dicek lagi di service
+	if contentType := header.Header.Get("Content-Type"); contentType != "" && contentType != "application/octet-stream" && !strings.HasPrefix(contentType, "image/") {
+		http.Error(w, services.ErrUnsupportedImage.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxImageSize+1))
+	if err != nil {
+		http.Error(w, "Gagal membaca gambar", http.StatusBadRequest)
+		return
+	}
+
+	produk, err := h.service.UploadImage(r.Context(), id, data)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", produkETag(produk))
+	json.NewEncoder(w).Encode(produk)
+}
+
+// DeleteImage - DELETE /api/produk/{id}/image
+func (h *ProdukHandler) DeleteImage(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.DeleteImage(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Gambar produk deleted successfully",
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"kasirApi/models"
+)
+
+// GetStockMovements - GET /api/produk/{id}/stock-movements
+func (h *ProdukHandler) GetStockMovements(w http.ResponseWriter, r *http.Request, id int) {
+	movements, err := h.service.GetStockMovements(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(movements)
+}
+
+// CreateStockAdjustment - POST /api/produk/{id}/stock-adjustments
+// {"type":"receiving","quantity":12,"reason":"barang datang","user":"budi","reference":"SJ-001"}
+// quantity bertanda, negatif mengurangi stok. variant_id wajib untuk produk bervarian
+func (h *ProdukHandler) CreateStockAdjustment(w http.ResponseWriter, r *http.Request, id int) {
+	var movement models.StockMovement
+	if err := json.NewDecoder(r.Body).Decode(&movement); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	movement.ID = 0
+	movement.ProdukID = id
+	if err := h.service.AdjustStock(r.Context(), &movement); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(movement)
+}
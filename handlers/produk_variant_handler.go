@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasirApi/models"
+	"net/http"
+	"strconv"
+)
+
+// HandleVariants - /api/produk/{id}/variants dan /api/produk/{id}/variants/{variantId}
+func (h *ProdukHandler) HandleVariants(w http.ResponseWriter, r *http.Request, produkID int, rest []string) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetVariants(w, r, produkID)
+		case http.MethodPost:
+			h.CreateVariant(w, r, produkID)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	if len(rest) > 1 {
+		http.NotFound(w, r)
+		return
+	}
+	variantID, err := strconv.Atoi(rest[0])
+	if err != nil {
+		http.Error(w, "Invalid variant ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetVariant(w, r, produkID, variantID)
+	case http.MethodPut:
+		h.UpdateVariant(w, r, produkID, variantID)
+	case http.MethodDelete:
+		h.DeleteVariant(w, r, produkID, variantID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetVariants - GET /api/produk/{id}/variants
+func (h *ProdukHandler) GetVariants(w http.ResponseWriter, r *http.Request, produkID int) {
+	variants, err := h.service.GetVariants(r.Context(), produkID)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(variants)
+}
+
+// GetVariant - GET /api/produk/{id}/variants/{variantId}
+func (h *ProdukHandler) GetVariant(w http.ResponseWriter, r *http.Request, produkID, variantID int) {
+	variant, err := h.service.GetVariant(r.Context(), produkID, variantID)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(variant)
+}
+
+// CreateVariant - POST /api/produk/{id}/variants
+func (h *ProdukHandler) CreateVariant(w http.ResponseWriter, r *http.Request, produkID int) {
+	var variant models.ProdukVariant
+	if err := json.NewDecoder(r.Body).Decode(&variant); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	variant.ProdukID = produkID
+	if err := h.service.CreateVariant(r.Context(), &variant); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(variant)
+}
+
+// UpdateVariant - PUT /api/produk/{id}/variants/{variantId}
+func (h *ProdukHandler) UpdateVariant(w http.ResponseWriter, r *http.Request, produkID, variantID int) {
+	var variant models.ProdukVariant
+	if err := json.NewDecoder(r.Body).Decode(&variant); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	variant.ID = variantID
+	variant.ProdukID = produkID
+	if err := h.service.UpdateVariant(r.Context(), &variant); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(variant)
+}
+
+// DeleteVariant - DELETE /api/produk/{id}/variants/{variantId}
+func (h *ProdukHandler) DeleteVariant(w http.ResponseWriter, r *http.Request, produkID, variantID int) {
+	if err := h.service.DeleteVariant(r.Context(), produkID, variantID); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Varian deleted successfully",
+	})
+}
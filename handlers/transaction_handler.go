@@ -32,11 +32,12 @@ func (h *TransactionHandler) Checkout (w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
 	}
 
-	transaction, err := h.service.Checkout(req.Items, true)
+	transaction, err := h.service.Checkout(r.Context(), req.Items, true)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -64,9 +65,9 @@ func (h *TransactionHandler) GetReport(w http.ResponseWriter, r *http.Request) {
 
 	// Panggil Service/Repo
 	// (Anggap kamu langsung panggil repo di sini, idealnya lewat Service dulu)
-	report, err := h.service.GetSalesReport(startDate, endDate)
+	report, err := h.service.GetSalesReport(r.Context(), startDate, endDate)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
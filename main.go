@@ -1,26 +1,29 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"github.com/spf13/viper"
 	"kasirApi/database"
 	"kasirApi/handlers"
-	"kasirApi/models"
+	"kasirApi/migrations"
+	"kasirApi/notifier"
 	"kasirApi/repositories"
+	"kasirApi/repositories/memory"
+	"kasirApi/seed"
 	"kasirApi/services"
+	"kasirApi/storage"
 	"log"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
+	"time"
 	// "data"
 )
 
 func main() {
-	// GET localhost:8080/api/Category/{id}
-	// PUT localhost:8080/api/Category/{id}
-	// DELETE localhost:8080/api/Category/{id}
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
@@ -29,35 +32,190 @@ func main() {
 		_ = viper.ReadInConfig()
 	}
 
+	defaultTimeouts := services.DefaultTimeouts()
+	viper.SetDefault("TIMEOUT_READ", defaultTimeouts.Read)
+	viper.SetDefault("TIMEOUT_WRITE", defaultTimeouts.Write)
+	viper.SetDefault("TIMEOUT_CHECKOUT", defaultTimeouts.Checkout)
+	viper.SetDefault("TIMEOUT_REPORT", defaultTimeouts.Report)
+
+	defaultDB := database.DefaultOptions()
+	viper.SetDefault("DB_MAX_OPEN_CONNS", defaultDB.MaxOpenConns)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", defaultDB.MaxIdleConns)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", defaultDB.ConnMaxLifetime)
+	viper.SetDefault("DB_CONN_MAX_IDLE_TIME", defaultDB.ConnMaxIdleTime)
+	viper.SetDefault("DB_CONNECT_RETRIES", defaultDB.ConnectRetries)
+	viper.SetDefault("DB_CONNECT_BACKOFF", defaultDB.ConnectBackoff)
+	viper.SetDefault("DB_CONNECT_MAX_BACKOFF", defaultDB.ConnectMaxBackoff)
+
+	viper.SetDefault("STORAGE_DIR", "uploads")
+	viper.SetDefault("IMAGE_BASE_URL", "/images")
+	viper.SetDefault("LOW_STOCK_WEBHOOK_TIMEOUT", 5*time.Second)
+
 	config := Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:        viper.GetString("PORT"),
+		DBConn:      viper.GetString("DB_CONN"),
+		AutoMigrate: viper.GetBool("AUTO_MIGRATE"),
+
+		// optional, report & list dibaca dari replica kalau diisi
+		DBReplicaConn: viper.GetString("DB_REPLICA_CONN"),
+		// optional, dipisah koma
+		SeedFiles: splitList(viper.GetString("SEED_FILES")),
+
+		// gambar produk disimpan di STORAGE_DIR, image_url = IMAGE_BASE_URL/{key}
+		StorageDir:   viper.GetString("STORAGE_DIR"),
+		ImageBaseURL: viper.GetString("IMAGE_BASE_URL"),
+
+		// alert stok menipis ditulis ke LOW_STOCK_LOG_FILE (kosong = stdout)
+		// dan, kalau diisi, di-POST ke LOW_STOCK_WEBHOOK_URL
+		LowStockLogFile:        viper.GetString("LOW_STOCK_LOG_FILE"),
+		LowStockWebhookURL:     viper.GetString("LOW_STOCK_WEBHOOK_URL"),
+		LowStockWebhookTimeout: viper.GetDuration("LOW_STOCK_WEBHOOK_TIMEOUT"),
+
+		DBMaxOpenConns:      viper.GetInt("DB_MAX_OPEN_CONNS"),
+		DBMaxIdleConns:      viper.GetInt("DB_MAX_IDLE_CONNS"),
+		DBConnMaxLifetime:   viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+		DBConnMaxIdleTime:   viper.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+		DBConnectRetries:    viper.GetInt("DB_CONNECT_RETRIES"),
+		DBConnectBackoff:    viper.GetDuration("DB_CONNECT_BACKOFF"),
+		DBConnectMaxBackoff: viper.GetDuration("DB_CONNECT_MAX_BACKOFF"),
+
+		// contoh: TIMEOUT_REPORT=1m, TIMEOUT_READ=2s
+		TimeoutRead:     viper.GetDuration("TIMEOUT_READ"),
+		TimeoutWrite:    viper.GetDuration("TIMEOUT_WRITE"),
+		TimeoutCheckout: viper.GetDuration("TIMEOUT_CHECKOUT"),
+		TimeoutReport:   viper.GetDuration("TIMEOUT_REPORT"),
 	}
 
-	// Setup database
-	db, err := database.InitDB(config.DBConn)
+	driver := database.DriverName(config.DBConn)
+
+	var (
+		db              *sql.DB
+		replica         *sql.DB
+		categoryRepo    repositories.CategoryRepository
+		produkRepo      repositories.ProdukRepository
+		variantRepo     repositories.ProdukVariantRepository
+		bundleRepo      repositories.ProdukBundleRepository
+		transactionRepo repositories.TransactionRepository
+		uow             repositories.UnitOfWork
+	)
+
+	if driver == database.DriverMemory {
+		// DB_CONN=memory:// -> semua data di RAM, hilang saat restart (demo / testing)
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			log.Fatal("migrate butuh DB_CONN postgres:// atau sqlite://")
+		}
+		store := memory.NewStore()
+		categoryRepo = memory.NewCategoryRepository(store)
+		produkRepo = memory.NewProdukRepository(store)
+		variantRepo = memory.NewProdukVariantRepository(store)
+		bundleRepo = memory.NewProdukBundleRepository(store)
+		transactionRepo = memory.NewTransactionRepository(store)
+		uow = memory.NewUnitOfWork(store)
+		log.Println("Running with in-memory repositories")
+	} else {
+		// Setup database
+		dbOptions := database.Options{
+			MaxOpenConns:      config.DBMaxOpenConns,
+			MaxIdleConns:      config.DBMaxIdleConns,
+			ConnMaxLifetime:   config.DBConnMaxLifetime,
+			ConnMaxIdleTime:   config.DBConnMaxIdleTime,
+			ConnectRetries:    config.DBConnectRetries,
+			ConnectBackoff:    config.DBConnectBackoff,
+			ConnectMaxBackoff: config.DBConnectMaxBackoff,
+		}
+		var err error
+		db, err = database.InitDB(config.DBConn, dbOptions)
+		if err != nil {
+			log.Fatal("Failed to initialize database: ", err)
+		}
+		defer db.Close()
+
+		// Subcommand: go run . migrate up|down|status
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(db, driver, os.Args[2:]); err != nil {
+				log.Fatal("Migration gagal: ", err)
+			}
+			return
+		}
+
+		if config.AutoMigrate {
+			count, err := migrations.Up(db, driver)
+			if err != nil {
+				log.Fatal("Migration gagal: ", err)
+			}
+			log.Printf("%d migration di-apply", count)
+		}
+
+		if config.DBReplicaConn != "" {
+			if database.DriverName(config.DBReplicaConn) != driver {
+				log.Fatal("DB_REPLICA_CONN harus memakai driver yang sama dengan DB_CONN")
+			}
+			replica, err = database.InitDB(config.DBReplicaConn, dbOptions)
+			if err != nil {
+				log.Fatal("Failed to initialize read replica: ", err)
+			}
+			defer replica.Close()
+			log.Println("Report dan list query diarahkan ke read replica")
+		}
+
+		categoryRepo = repositories.NewCategoryRepository(db, replica)
+		produkRepo = repositories.NewProdukRepository(db, replica, driver)
+		variantRepo = repositories.NewProdukVariantRepository(db, replica)
+		bundleRepo = repositories.NewProdukBundleRepository(db, replica)
+		transactionRepo = repositories.NewTransactionRepository(db, replica)
+		uow = repositories.NewUnitOfWork(db)
+	}
+
+	timeouts := services.Timeouts{
+		Read:     config.TimeoutRead,
+		Write:    config.TimeoutWrite,
+		Checkout: config.TimeoutCheckout,
+		Report:   config.TimeoutReport,
+	}
+
+	images, err := storage.NewLocalStorage(config.StorageDir, config.ImageBaseURL)
 	if err != nil {
-		log.Fatal("Failed to initialze database", err)
+		log.Fatal("Gagal menyiapkan storage gambar: ", err)
 	}
-	defer db.Close()
 
-	categoryRepo := repositories.NewCategoryRepository(db)
-	categoryService := services.NewCategoryService(categoryRepo)
-	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	// Category
+	categoryService := services.NewCategoryService(categoryRepo, timeouts)
 	// Produk
-	produkRepo := repositories.NewProdukRepository(db)
-	produkService := services.NewProdukService(produkRepo)
+	produkService := services.NewProdukService(produkRepo, categoryRepo, variantRepo, bundleRepo, images, timeouts)
 	produkHandler := handlers.NewProdukHandler(produkService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService, produkService)
+	produkCSVHandler := handlers.NewProdukCSVHandler(services.NewProdukCSVService(produkService, categoryService, uow))
 	// Transaction
-	transactionRepo := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepo)
+	stockAlerts, err := newStockNotifier(config)
+	if err != nil {
+		log.Fatal("Gagal menyiapkan alert stok: ", err)
+	}
+	transactionService := services.NewTransactionService(transactionRepo, produkRepo, uow, stockAlerts, timeouts)
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
 
+	// Subcommand: go run . seed fixtures/demo.yaml
+	seeder := seed.NewSeeder(categoryService, produkService, uow)
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if driver == database.DriverMemory {
+			log.Fatal("seed butuh DB_CONN postgres:// atau sqlite://, pakai SEED_FILES untuk memory://")
+		}
+		if err := runSeed(context.Background(), seeder, os.Args[2:]); err != nil {
+			log.Fatal("Seed gagal: ", err)
+		}
+		return
+	}
+
+	// SEED_FILES=fixtures/demo.yaml -> isi katalog saat start, berguna untuk memory://
+	if len(config.SeedFiles) > 0 {
+		if err := runSeed(context.Background(), seeder, config.SeedFiles); err != nil {
+			log.Fatal("Seed gagal: ", err)
+		}
+	}
+
 	http.HandleFunc("/api/checkout", transactionHandler.HandleCheckout) // POST
 	// for general and specified date report
 	http.HandleFunc("/api/report", transactionHandler.GetReport)
 	http.HandleFunc("/api/report/hari-ini", transactionHandler.GetReport)
-	
 
 	// Setup routes
 	http.HandleFunc("/api/category", categoryHandler.HandleCategory)
@@ -65,42 +223,16 @@ func main() {
 
 	http.HandleFunc("/api/produk", produkHandler.HandleProduk)
 	http.HandleFunc("/api/produk/", produkHandler.HandleProdukByID)
+	http.HandleFunc("/api/produk/search", produkHandler.Search)
+	http.HandleFunc("/api/produk/low-stock", produkHandler.LowStock)
+	http.HandleFunc("/api/produk/import", produkCSVHandler.Import)
+	http.HandleFunc("/api/produk/export", produkCSVHandler.Export)
+	http.Handle("/images/", handlers.NewImageHandler(images))
 
-	http.HandleFunc("/api/Category/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == "GET" {
-			getCategoryByID(w, r)
-		} else if r.Method == "PUT" {
-			updateCategory(w, r)
-		} else if r.Method == "DELETE" {
-			deleteCategory(w, r)
-		}
-
-	})
-
-	// GET localhost:8080/api/Category
-	// POST localhost:8080/api/Category
-	// http.HandleFunc("/api/Category", func(w http.ResponseWriter, r *http.Request) {
-	// 	if r.Method == "GET" {
-	// 		w.Header().Set("Content-Type", "application/json")
-	// 		json.NewEncoder(w).Encode(Category)
-	// 	} else if r.Method == "POST" {
-	// 		// baca data from reqquest
-	// 		var CategoryBaru Category
-	// 		err := json.NewDecoder(r.Body).Decode(&CategoryBaru)
-	// 		if err != nil {
-	// 			http.Error(w, "Invalid request", http.StatusBadRequest)
-	// 			return
-	// 		}
-
-	// 		// masukin data to variable Category
-	// 		CategoryBaru.ID = len(Category) + 1
-	// 		Category = append(Category, CategoryBaru)
-	// 		w.Header().Set("Content-Type", "application/json")
-	// 		w.WriteHeader(http.StatusCreated) //Success or 201
-	// 		json.NewEncoder(w).Encode(CategoryBaru)
-	// 	}
-
-	// })
+	// Health check: live = proses jalan, ready = database & migration siap
+	healthHandler := handlers.NewHealthHandler(db, replica, driver)
+	http.HandleFunc("/health/live", healthHandler.Live)
+	http.HandleFunc("/health/ready", healthHandler.Ready)
 
 	// localhost:8080/health
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -127,93 +259,58 @@ func main() {
 }
 
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBConn string `mapstructure:"DB_CONN"`
-}
-
-var Category = []models.Category{
-	{ID: 1, Name: "Makanan"},
-	{ID: 2, Name: "Minuman"},
-	{ID: 3, Name: "Camilan"},
+	Port          string   `mapstructure:"PORT"`
+	DBConn        string   `mapstructure:"DB_CONN"`
+	DBReplicaConn string   `mapstructure:"DB_REPLICA_CONN"`
+	SeedFiles     []string `mapstructure:"SEED_FILES"`
+	AutoMigrate   bool     `mapstructure:"AUTO_MIGRATE"`
+
+	StorageDir   string `mapstructure:"STORAGE_DIR"`
+	ImageBaseURL string `mapstructure:"IMAGE_BASE_URL"`
+
+	LowStockLogFile        string        `mapstructure:"LOW_STOCK_LOG_FILE"`
+	LowStockWebhookURL     string        `mapstructure:"LOW_STOCK_WEBHOOK_URL"`
+	LowStockWebhookTimeout time.Duration `mapstructure:"LOW_STOCK_WEBHOOK_TIMEOUT"`
+
+	DBMaxOpenConns      int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns      int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime   time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime   time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+	DBConnectRetries    int           `mapstructure:"DB_CONNECT_RETRIES"`
+	DBConnectBackoff    time.Duration `mapstructure:"DB_CONNECT_BACKOFF"`
+	DBConnectMaxBackoff time.Duration `mapstructure:"DB_CONNECT_MAX_BACKOFF"`
+
+	TimeoutRead     time.Duration `mapstructure:"TIMEOUT_READ"`
+	TimeoutWrite    time.Duration `mapstructure:"TIMEOUT_WRITE"`
+	TimeoutCheckout time.Duration `mapstructure:"TIMEOUT_CHECKOUT"`
+	TimeoutReport   time.Duration `mapstructure:"TIMEOUT_REPORT"`
 }
 
-func getCategoryByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/Category/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
-		return
-	}
-
-	// membaca slice dari ram
-	for _, p := range Category {
-		if p.ID == id {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(p)
-			return
+// newStockNotifier - notifier alert stok menipis sesuai config
+func newStockNotifier(config Config) (notifier.Notifier, error) {
+	var logger notifier.Notifier = notifier.NewLogNotifier(os.Stdout)
+	if config.LowStockLogFile != "" {
+		file, err := notifier.NewFileNotifier(config.LowStockLogFile)
+		if err != nil {
+			return nil, err
 		}
+		logger = file
 	}
 
-	http.Error(w, "Category belum ada", http.StatusNotFound)
-
-}
-
-func updateCategory(w http.ResponseWriter, r *http.Request) {
-	// get id dari request
-	idStr :=
-		strings.TrimPrefix(r.URL.Path, "/api/Category/")
-
-	// ganti int
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
-		return
+	if config.LowStockWebhookURL == "" {
+		return logger, nil
 	}
-
-	//get data dari request
-	var updateCategory models.Category
-	err = json.NewDecoder(r.Body).Decode(&updateCategory)
-	if err != nil {
-		http.Error(w, "Invalid Request", http.StatusBadRequest)
-		return
-
-	}
-
-	// loop Category, find id, change data type as requested
-	for i := range Category {
-		if Category[i].ID == id {
-			updateCategory.ID = id
-			Category[i] = updateCategory
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(updateCategory)
-			return
-		}
-	}
-	http.Error(w, "Category belum ada", http.StatusNotFound)
+	webhook := notifier.NewWebhookNotifier(config.LowStockWebhookURL, config.LowStockWebhookTimeout)
+	return notifier.Multi{logger, webhook}, nil
 }
 
-func deleteCategory(w http.ResponseWriter, r *http.Request) {
-	// get id
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/Category/")
-	//  ganti id to int
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid Category ID", http.StatusBadRequest)
-		return
-	}
-	// loop Category cari ID, dapat index yg mau dihapus
-	for i, p := range Category {
-		if p.ID == id {
-			// bikin slicec baru dengan data sebelum dan sesudah indexing
-			Category = append(Category[:i], Category[i+1:]...)
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]string{
-				"message": "sukses delete",
-			})
-
-			return
+// splitList - "a.yaml, b.json" -> ["a.yaml", "b.json"]
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
-	http.Error(w, "Category belum ada", http.StatusNotFound)
+	return items
 }
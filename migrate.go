@@ -0,0 +1,49 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"kasirApi/migrations"
+)
+
+// runMigrate - subcommand: kasirApi migrate up|down|status
+func runMigrate(db *sql.DB, driver string, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down|status")
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := migrations.Up(db, driver)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migration di-apply\n", count)
+	case "down":
+		m, err := migrations.Down(db, driver)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("tidak ada migration untuk di-rollback")
+			return nil
+		}
+		fmt.Printf("rollback %04d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := migrations.GetStatus(db, driver)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		return fmt.Errorf("perintah migrate tidak dikenal: %s", args[0])
+	}
+
+	return nil
+}
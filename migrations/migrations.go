@@ -0,0 +1,266 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kasirApi/database"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Migration - satu versi schema, berisi SQL up dan down
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status - status satu migration di database
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+const createTableQuery = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`
+
+// Load - baca semua migration yang di-embed untuk driver tertentu, urut berdasarkan versi.
+// Nama file: <driver>/0001_nama.up.sql dan <driver>/0001_nama.down.sql
+func Load(driver string) ([]Migration, error) {
+	var dir string
+	switch driver {
+	case database.DriverPostgres:
+		dir = "postgres"
+	case database.DriverSQLite:
+		dir = "sqlite"
+	default:
+		return nil, fmt.Errorf("driver %s tidak punya migration", driver)
+	}
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("nama migration tidak valid: %s", fileName)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("versi migration tidak valid: %s", fileName)
+		}
+
+		content, err := fs.ReadFile(files, dir+"/"+fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s tidak punya file up", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up - jalankan semua migration yang belum di-apply.
+// Return jumlah migration yang dijalankan
+func Up(db *sql.DB, driver string) (int, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return 0, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := runInTx(db, m.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name)
+		if err != nil {
+			return count, fmt.Errorf("migration %04d_%s gagal: %w", m.Version, m.Name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down - rollback satu migration terakhir yang sudah di-apply.
+// Return migration yang di-rollback, atau nil kalau tidak ada
+func Down(db *sql.DB, driver string) (*Migration, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s tidak punya file down", m.Version, m.Name)
+		}
+		err := runInTx(db, m.Down, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+		if err != nil {
+			return nil, fmt.Errorf("rollback %04d_%s gagal: %w", m.Version, m.Name, err)
+		}
+		return &m, nil
+	}
+
+	return nil, nil
+}
+
+// GetStatus - daftar semua migration beserta status apply-nya
+func GetStatus(db *sql.DB, driver string) ([]Status, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		s := Status{Version: m.Version, Name: m.Name}
+		if appliedAt, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, s)
+	}
+
+	return statuses, nil
+}
+
+// Pending - jumlah migration yang belum di-apply. Tidak membuat tabel
+// schema_migrations, jadi aman dipanggil dari health check
+func Pending(ctx context.Context, db *sql.DB, driver string) (int, error) {
+	migrations, err := Load(driver)
+	if err != nil {
+		return 0, err
+	}
+
+	applied, err := readApplied(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+func appliedVersions(db *sql.DB) (map[int]time.Time, error) {
+	if _, err := db.Exec(createTableQuery); err != nil {
+		return nil, err
+	}
+
+	return readApplied(context.Background(), db)
+}
+
+func readApplied(ctx context.Context, db *sql.DB) (map[int]time.Time, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// runInTx - jalankan script migration dan catat ke schema_migrations dalam satu transaksi
+func runInTx(db *sql.DB, script string, recordQuery string, args ...interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(recordQuery, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
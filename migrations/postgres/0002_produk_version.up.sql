@@ -0,0 +1 @@
+ALTER TABLE produk ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_produk_category_id;
+
+ALTER TABLE produk DROP COLUMN category_id;
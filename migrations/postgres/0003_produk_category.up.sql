@@ -0,0 +1,3 @@
+ALTER TABLE produk ADD COLUMN category_id INTEGER REFERENCES category(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_produk_category_id ON produk(category_id);
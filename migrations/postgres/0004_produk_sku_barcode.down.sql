@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS produk_barcodes;
+
+DROP INDEX IF EXISTS idx_produk_sku;
+
+ALTER TABLE produk DROP COLUMN sku;
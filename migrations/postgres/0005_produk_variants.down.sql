@@ -0,0 +1,4 @@
+ALTER TABLE transaction_details DROP COLUMN variant_name;
+ALTER TABLE transaction_details DROP COLUMN variant_id;
+
+DROP TABLE IF EXISTS produk_variants;
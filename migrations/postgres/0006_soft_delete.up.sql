@@ -0,0 +1,2 @@
+ALTER TABLE produk ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE category ADD COLUMN deleted_at TIMESTAMP;
@@ -0,0 +1 @@
+ALTER TABLE produk DROP COLUMN image_key;
@@ -0,0 +1 @@
+ALTER TABLE produk ADD COLUMN image_key VARCHAR(255);
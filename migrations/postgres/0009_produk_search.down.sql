@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_produk_name_fts;
+DROP INDEX IF EXISTS idx_category_name_trgm;
+DROP INDEX IF EXISTS idx_produk_sku_trgm;
+DROP INDEX IF EXISTS idx_produk_name_trgm;
@@ -0,0 +1,9 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Trigram untuk nama yang salah ketik ("indomi" -> "Indomie"), juga dipakai LIKE '%..%'
+CREATE INDEX IF NOT EXISTS idx_produk_name_trgm ON produk USING gin (LOWER(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_produk_sku_trgm ON produk USING gin (LOWER(sku) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_category_name_trgm ON category USING gin (LOWER(name) gin_trgm_ops);
+
+-- Full-text untuk pencarian per kata
+CREATE INDEX IF NOT EXISTS idx_produk_name_fts ON produk USING gin (to_tsvector('simple', name));
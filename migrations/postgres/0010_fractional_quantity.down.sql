@@ -0,0 +1,7 @@
+ALTER TABLE transaction_details DROP COLUMN unit;
+ALTER TABLE produk DROP COLUMN unit;
+
+-- Pecahan dibulatkan, tidak bisa dikembalikan persis
+ALTER TABLE transaction_details ALTER COLUMN quantity TYPE INTEGER USING ROUND(quantity);
+ALTER TABLE produk_variants ALTER COLUMN stock TYPE INTEGER USING ROUND(stock);
+ALTER TABLE produk ALTER COLUMN stock TYPE INTEGER USING ROUND(stock);
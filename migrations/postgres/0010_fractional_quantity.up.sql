@@ -0,0 +1,7 @@
+-- Stok dan quantity boleh pecahan (0.75 kg), 3 angka di belakang koma
+ALTER TABLE produk ALTER COLUMN stock TYPE NUMERIC(14,3);
+ALTER TABLE produk_variants ALTER COLUMN stock TYPE NUMERIC(14,3);
+ALTER TABLE transaction_details ALTER COLUMN quantity TYPE NUMERIC(14,3);
+
+ALTER TABLE produk ADD COLUMN unit VARCHAR(16) NOT NULL DEFAULT 'pcs';
+ALTER TABLE transaction_details ADD COLUMN unit VARCHAR(16);
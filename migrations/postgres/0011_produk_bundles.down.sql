@@ -0,0 +1,4 @@
+DELETE FROM transaction_details WHERE bundle_id IS NOT NULL;
+ALTER TABLE transaction_details DROP COLUMN bundle_id;
+
+DROP TABLE IF EXISTS produk_bundle_items;
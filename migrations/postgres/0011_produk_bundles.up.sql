@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS produk_bundle_items (
+    id           SERIAL PRIMARY KEY,
+    bundle_id    INTEGER NOT NULL REFERENCES produk(id) ON DELETE CASCADE,
+    component_id INTEGER NOT NULL REFERENCES produk(id),
+    quantity     NUMERIC(14,3) NOT NULL,
+    UNIQUE (bundle_id, component_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_produk_bundle_items_component_id ON produk_bundle_items(component_id);
+
+-- Baris komponen paket di detail transaksi, menunjuk ke produk paketnya
+ALTER TABLE transaction_details ADD COLUMN bundle_id INTEGER REFERENCES produk(id);
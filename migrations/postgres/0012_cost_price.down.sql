@@ -0,0 +1,5 @@
+ALTER TABLE transaction_details DROP COLUMN cost;
+ALTER TABLE transaction_details DROP COLUMN unit_cost;
+
+ALTER TABLE produk_variants DROP COLUMN cost_price;
+ALTER TABLE produk DROP COLUMN cost_price;
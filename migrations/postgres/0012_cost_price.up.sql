@@ -0,0 +1,7 @@
+ALTER TABLE produk ADD COLUMN cost_price INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE produk_variants ADD COLUMN cost_price INTEGER NOT NULL DEFAULT 0;
+
+-- Snapshot HPP per baris transaksi. Transaksi lama tidak tahu harga pokoknya,
+-- tetap 0 sehingga laba periode lama terlihat sama dengan revenue
+ALTER TABLE transaction_details ADD COLUMN unit_cost INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN cost INTEGER NOT NULL DEFAULT 0;
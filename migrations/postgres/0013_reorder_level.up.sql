@@ -0,0 +1,2 @@
+-- Batas stok minimum per produk, 0 = tidak dipantau
+ALTER TABLE produk ADD COLUMN reorder_level NUMERIC(14,3) NOT NULL DEFAULT 0;
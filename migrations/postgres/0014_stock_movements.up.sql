@@ -0,0 +1,19 @@
+-- Buku stok append-only, stok produk / varian = jumlah quantity movement-nya
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id         SERIAL PRIMARY KEY,
+    produk_id  INTEGER NOT NULL REFERENCES produk(id) ON DELETE CASCADE,
+    -- Tanpa foreign key supaya riwayat varian tetap ada walau variannya dihapus
+    variant_id INTEGER,
+    type       VARCHAR(16) NOT NULL CHECK (type IN ('sale', 'refund', 'adjustment', 'receiving', 'transfer')),
+    quantity   NUMERIC(14,3) NOT NULL,
+    reason     TEXT NOT NULL DEFAULT '',
+    user_name  VARCHAR(100) NOT NULL DEFAULT '',
+    reference  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_produk_id ON stock_movements(produk_id, id);
+
+-- Stok saat ini jadi saldo awal buku stok
+INSERT INTO stock_movements (produk_id, type, quantity, reason) SELECT id, 'adjustment', stock, 'saldo awal' FROM produk WHERE stock <> 0;
+INSERT INTO stock_movements (produk_id, variant_id, type, quantity, reason) SELECT produk_id, id, 'adjustment', stock, 'saldo awal' FROM produk_variants WHERE stock <> 0;
@@ -0,0 +1,2 @@
+DELETE FROM produk_variants WHERE deleted_at IS NOT NULL;
+ALTER TABLE produk_variants DROP COLUMN deleted_at;
@@ -0,0 +1,3 @@
+-- Varian diarsipkan, bukan dihapus: riwayat harga, buku stok dan detail
+-- transaksi tetap menunjuk ke variannya
+ALTER TABLE produk_variants ADD COLUMN deleted_at TIMESTAMP;
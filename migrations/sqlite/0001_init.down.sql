@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS transaction_details;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS produk;
+DROP TABLE IF EXISTS category;
@@ -0,0 +1,10 @@
+ALTER TABLE produk ADD COLUMN sku VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_produk_sku ON produk(sku);
+
+CREATE TABLE IF NOT EXISTS produk_barcodes (
+    barcode   VARCHAR(64) PRIMARY KEY,
+    produk_id INTEGER NOT NULL REFERENCES produk(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_produk_barcodes_produk_id ON produk_barcodes(produk_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS produk_variants (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    produk_id INTEGER NOT NULL REFERENCES produk(id) ON DELETE CASCADE,
+    name      VARCHAR(255) NOT NULL,
+    price     INTEGER NOT NULL DEFAULT 0,
+    stock     INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_produk_variants_produk_id ON produk_variants(produk_id);
+
+ALTER TABLE transaction_details ADD COLUMN variant_id INTEGER REFERENCES produk_variants(id) ON DELETE SET NULL;
+ALTER TABLE transaction_details ADD COLUMN variant_name VARCHAR(255);
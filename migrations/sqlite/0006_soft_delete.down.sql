@@ -0,0 +1,2 @@
+ALTER TABLE category DROP COLUMN deleted_at;
+ALTER TABLE produk DROP COLUMN deleted_at;
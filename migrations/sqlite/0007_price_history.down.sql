@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS produk_price_history;
+
+ALTER TABLE transaction_details DROP COLUMN unit_price;
@@ -0,0 +1,18 @@
+ALTER TABLE transaction_details ADD COLUMN unit_price INTEGER NOT NULL DEFAULT 0;
+
+-- Detail lama belum punya harga satuan, hitung balik dari subtotal
+UPDATE transaction_details SET unit_price = subtotal / quantity WHERE quantity > 0;
+
+CREATE TABLE IF NOT EXISTS produk_price_history (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    produk_id      INTEGER NOT NULL REFERENCES produk(id) ON DELETE CASCADE,
+    variant_id     INTEGER REFERENCES produk_variants(id) ON DELETE CASCADE,
+    price          INTEGER NOT NULL,
+    effective_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_produk_price_history_produk_id ON produk_price_history(produk_id, effective_from);
+
+-- Harga saat ini jadi titik awal riwayat
+INSERT INTO produk_price_history (produk_id, price) SELECT id, price FROM produk;
+INSERT INTO produk_price_history (produk_id, variant_id, price) SELECT produk_id, id, price FROM produk_variants;
@@ -0,0 +1,4 @@
+-- SQLite tidak punya pg_trgm, ranking pencarian dihitung di Go
+-- (repositories.RankProduk). Versi ini hanya menjaga nomor migration
+-- tetap sama dengan Postgres.
+SELECT 1;
@@ -0,0 +1,7 @@
+ALTER TABLE transaction_details DROP COLUMN unit;
+ALTER TABLE produk DROP COLUMN unit;
+
+-- Pecahan dibulatkan, tidak bisa dikembalikan persis
+UPDATE transaction_details SET quantity = CAST(ROUND(quantity) AS INTEGER);
+UPDATE produk_variants SET stock = CAST(ROUND(stock) AS INTEGER);
+UPDATE produk SET stock = CAST(ROUND(stock) AS INTEGER);
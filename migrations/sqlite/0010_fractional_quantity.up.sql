@@ -0,0 +1,5 @@
+-- Kolom INTEGER SQLite tetap menyimpan pecahan (0.75) sebagai REAL,
+-- jadi stok dan quantity tidak perlu diubah tipenya. Aplikasi membulatkan
+-- ke 3 angka di belakang koma saat membaca
+ALTER TABLE produk ADD COLUMN unit VARCHAR(16) NOT NULL DEFAULT 'pcs';
+ALTER TABLE transaction_details ADD COLUMN unit VARCHAR(16);
@@ -0,0 +1 @@
+ALTER TABLE produk DROP COLUMN reorder_level;
@@ -1,6 +1,10 @@
 package models
 
+import "time"
+
 type Category struct {
 	ID    int    `json:"id"`
 	Name  string `json:"name"`
-}
\ No newline at end of file
+	// DeletedAt - diisi kalau category diarsipkan (soft delete)
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// ImportRowError - kesalahan di satu baris file import. Row dihitung dari
+// baris pertama file (header = baris 1) supaya gampang dicari di spreadsheet
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportResult - hasil import katalog. Kalau Errors tidak kosong tidak ada
+// yang disimpan, begitu juga kalau DryRun
+type ImportResult struct {
+	DryRun            bool             `json:"dry_run"`
+	Rows              int              `json:"rows"`
+	CategoriesCreated int              `json:"categories_created"`
+	ProdukCreated     int              `json:"produk_created"`
+	ProdukUpdated     int              `json:"produk_updated"`
+	Errors            []ImportRowError `json:"errors"`
+}
@@ -1,9 +1,140 @@
 package models
 
+import "time"
+
 type Produk struct {
 	ID    int    `json:"id"`
 	CategoryID int    `json:"category_id"`
 	Name  string `json:"name"`
 	Price int    `json:"price"`
-	Stock  int    `json:"stock"`
-}
\ No newline at end of file
+	// CostPrice - harga pokok (modal) per unit, dipakai untuk HPP dan laba.
+	// Produk paket tidak memakai ini, HPP-nya dari komponen
+	CostPrice int `json:"cost_price"`
+	Stock  Quantity `json:"stock"`
+	// ReorderLevel - batas stok minimum, stok <= batas ini dianggap menipis
+	// dan checkout yang melewatinya mengirim alert. 0 = tidak dipantau
+	ReorderLevel Quantity `json:"reorder_level"`
+	// Unit - satuan stok dan penjualan (pcs, kg, l, ...), kosong = pcs
+	Unit string `json:"unit"`
+	// SKU - kode unik internal toko, opsional
+	SKU string `json:"sku,omitempty"`
+	// Barcodes - boleh lebih dari satu (misal kemasan lama & baru).
+	// Saat update, field ini tidak dikirim = barcode tidak diubah
+	Barcodes []string `json:"barcodes,omitempty"`
+	// Variants - varian produk, read-only di sini.
+	// Tambah/ubah lewat /api/produk/{id}/variants
+	Variants []ProdukVariant `json:"variants,omitempty"`
+	// BundleItems - komponen kalau produk ini paket (misal Paket Hemat),
+	// read-only di sini. Ubah lewat /api/produk/{id}/bundle. Stok produk
+	// paket tidak dipakai, checkout mengurangi stok komponennya
+	BundleItems []BundleItem `json:"bundle_items,omitempty"`
+	// ImageKey - key file gambar di storage, URL publiknya di ImageURL
+	ImageKey string `json:"-"`
+	// ImageURL & ThumbnailURL - diisi service dari ImageKey, kosong kalau belum ada gambar
+	ImageURL     string `json:"image_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	// Version - naik setiap produk berubah, dipakai untuk ETag / If-Match
+	Version int `json:"version"`
+	// DeletedAt - diisi kalau produk diarsipkan (soft delete). Produk arsip
+	// tidak muncul di list dan tidak bisa dijual, tapi riwayat transaksi tetap utuh
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Category - hanya diisi kalau diminta (?expand=category)
+	Category *Category `json:"category,omitempty"`
+}
+
+// DefaultUnit - satuan produk yang tidak menyebut satuan
+const DefaultUnit = "pcs"
+
+// Units - satuan yang dikenal, true kalau boleh dijual pecahan (0.75 kg).
+// Satuan hitungan seperti pcs hanya boleh bilangan bulat
+var Units = map[string]bool{
+	"pcs":  false,
+	"pack": false,
+	"box":  false,
+	"kg":   true,
+	"g":    true,
+	"l":    true,
+	"ml":   true,
+	"m":    true,
+	"cm":   true,
+}
+
+// ProdukVariant - varian satu produk (misal Kopi Susu Large / Iced),
+// harga dan stok dihitung per varian, laporan tetap masuk ke produk induk
+type ProdukVariant struct {
+	ID       int    `json:"id"`
+	ProdukID int    `json:"produk_id"`
+	Name     string `json:"name"`
+	Price    int    `json:"price"`
+	// CostPrice - harga pokok varian per unit
+	CostPrice int `json:"cost_price"`
+	// Stock - satuannya ikut Unit produk induk
+	Stock Quantity `json:"stock"`
+	// DeletedAt - varian yang dihapus hanya diarsipkan supaya riwayat harga,
+	// buku stok dan detail transaksi tetap utuh. Tidak muncul di list
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// BundleItem - satu komponen paket: Quantity unit komponen per satu paket
+type BundleItem struct {
+	BundleID      int      `json:"bundle_id"`
+	ComponentID   int      `json:"component_id"`
+	ComponentName string   `json:"component_name,omitempty"`
+	Quantity      Quantity `json:"quantity"`
+}
+
+// PriceHistory - satu perubahan harga produk (atau varian kalau VariantID diisi),
+// berlaku mulai EffectiveFrom sampai ada harga berikutnya
+type PriceHistory struct {
+	ID            int       `json:"id"`
+	ProdukID      int       `json:"produk_id"`
+	VariantID     int       `json:"variant_id,omitempty"`
+	Price         int       `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// ProdukSearchResult - produk hasil pencarian beserta skor relevansinya
+// (makin besar makin cocok, urutan hasil dari skor tertinggi)
+type ProdukSearchResult struct {
+	Produk
+	Score float64 `json:"score"`
+}
+
+// ProdukFilter - filter, urutan dan pagination untuk list produk
+type ProdukFilter struct {
+	Name       string
+	CategoryID int
+	MinPrice   *int
+	MaxPrice   *int
+	InStock    bool
+	// LowStock - hanya produk yang stoknya sudah di bawah / sama dengan ReorderLevel
+	LowStock bool
+
+	// IncludeArchived - ikut tampilkan produk yang sudah diarsipkan
+	IncludeArchived bool
+
+	ExpandCategory bool
+
+	// Sort - id, name, price atau stock. Kosong = id
+	Sort string
+	Desc bool
+
+	// Limit 0 = tanpa batas. Kalau Cursor diisi, Offset diabaikan
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// ProdukPage - satu halaman hasil list produk
+type ProdukPage struct {
+	Data       []Produk `json:"data"`
+	Total      int      `json:"total"`
+	Limit      int      `json:"limit"`
+	Offset     int      `json:"offset"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+// IsLowStock - stok sudah mencapai batas reorder. Selalu false kalau
+// ReorderLevel 0 (tidak dipantau)
+func (p Produk) IsLowStock() bool {
+	return p.ReorderLevel > 0 && p.Stock <= p.ReorderLevel
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// QuantityScale - Quantity disimpan dalam per-seribu satuan (3 angka di
+// belakang koma), cukup untuk gram dari kg atau ml dari liter
+const QuantityScale = 1000
+
+// Quantity - jumlah stok / barang terjual dalam satuan produk, boleh pecahan
+// (0.75 kg). Disimpan sebagai integer per-seribu supaya penjumlahan dan
+// pengurangan stok tidak kena error pembulatan float
+type Quantity int64
+
+var ErrInvalidQuantity = errors.New("quantity harus angka desimal, maksimal 3 angka di belakang koma")
+
+// NewQuantity - quantity dari bilangan bulat satuan
+func NewQuantity(units int) Quantity {
+	return Quantity(units) * QuantityScale
+}
+
+// ParseQuantity - baca "2", "0.75" atau "-1.5". Lebih dari 3 angka di
+// belakang koma ditolak, tidak dibulatkan diam-diam
+func ParseQuantity(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || len(frac) > 3 || !isDigits(whole) || !isDigits(frac) {
+		return 0, ErrInvalidQuantity
+	}
+
+	var q int64
+	if whole != "" {
+		n, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil || n > math.MaxInt64/QuantityScale {
+			return 0, ErrInvalidQuantity
+		}
+		q = n * QuantityScale
+	}
+	if frac != "" {
+		n, _ := strconv.ParseInt(frac+strings.Repeat("0", 3-len(frac)), 10, 64)
+		q += n
+	}
+
+	if negative {
+		q = -q
+	}
+	return Quantity(q), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsWhole - true kalau tidak ada pecahan
+func (q Quantity) IsWhole() bool {
+	return q%QuantityScale == 0
+}
+
+// String - bentuk desimal tanpa nol di belakang, contoh "10", "0.75"
+func (q Quantity) String() string {
+	sign := ""
+	n := int64(q)
+	if n < 0 {
+		sign, n = "-", -n
+	}
+
+	s := sign + strconv.FormatInt(n/QuantityScale, 10)
+	if frac := n % QuantityScale; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%03d", frac), "0")
+	}
+	return s
+}
+
+// Mul - q x n, dibulatkan ke 3 angka di belakang koma (setengah ke atas)
+func (q Quantity) Mul(n Quantity) Quantity {
+	total := int64(q) * int64(n)
+	if total < 0 {
+		return -Quantity((-total + QuantityScale/2) / QuantityScale)
+	}
+	return Quantity((total + QuantityScale/2) / QuantityScale)
+}
+
+// MulPrice - harga satuan x quantity dalam rupiah, dibulatkan ke rupiah
+// terdekat (setengah ke atas, menjauhi nol untuk angka negatif)
+func (q Quantity) MulPrice(price int) int {
+	total := int64(price) * int64(q)
+	if total < 0 {
+		return -int((-total + QuantityScale/2) / QuantityScale)
+	}
+	return int((total + QuantityScale/2) / QuantityScale)
+}
+
+// MarshalJSON - ditulis sebagai angka JSON, contoh 0.75
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON - terima angka (1.5) atau string ("1.5"), dibaca langsung
+// dari teksnya tanpa lewat float
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+
+	parsed, err := ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// UnmarshalText - untuk format teks lain seperti YAML fixture
+func (q *Quantity) UnmarshalText(text []byte) error {
+	parsed, err := ParseQuantity(string(text))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// Scan - dari NUMERIC Postgres ([]byte) atau INTEGER/REAL SQLite.
+// Nilai REAL dibulatkan ke 3 angka di belakang koma
+func (q *Quantity) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*q = 0
+	case int64:
+		*q = NewQuantity(int(v))
+	case float64:
+		*q = Quantity(math.Round(v * QuantityScale))
+	case []byte:
+		return q.scanString(string(v))
+	case string:
+		return q.scanString(v)
+	default:
+		return fmt.Errorf("tidak bisa membaca %T sebagai quantity", src)
+	}
+	return nil
+}
+
+func (q *Quantity) scanString(s string) error {
+	parsed, err := ParseQuantity(s)
+	if err != nil {
+		// NUMERIC tanpa skala bisa punya lebih dari 3 desimal, misal hasil SUM
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return err
+		}
+		parsed = Quantity(math.Round(f * QuantityScale))
+	}
+	*q = parsed
+	return nil
+}
+
+// Value - dikirim sebagai teks desimal, Postgres dan SQLite sama-sama
+// mengubahnya ke angka sesuai tipe kolom
+func (q Quantity) Value() (driver.Value, error) {
+	return q.String(), nil
+}
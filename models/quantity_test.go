@@ -0,0 +1,244 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Quantity
+		wantErr bool
+	}{
+		{in: "2", want: 2000},
+		{in: "0.75", want: 750},
+		{in: "0.5", want: 500},
+		{in: "1.005", want: 1005},
+		{in: ".5", want: 500},
+		{in: "3.", want: 3000},
+		{in: " 12 ", want: 12000},
+		{in: "-1.5", want: -1500},
+		{in: "-0.001", want: -1},
+		{in: "0", want: 0},
+		{in: "1.0005", wantErr: true},
+		{in: "-0.1234", wantErr: true},
+		{in: "", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "+1", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "9223372036854776", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseQuantity(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseQuantity(%q) = %d, mau error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseQuantity(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuantity(%q) = %d, mau %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuantityString(t *testing.T) {
+	tests := []struct {
+		q    Quantity
+		want string
+	}{
+		{q: 0, want: "0"},
+		{q: 2000, want: "2"},
+		{q: 750, want: "0.75"},
+		{q: 1005, want: "1.005"},
+		{q: 1500, want: "1.5"},
+		{q: -1500, want: "-1.5"},
+		{q: -1, want: "-0.001"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.want {
+			t.Errorf("Quantity(%d).String() = %q, mau %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestQuantityMul(t *testing.T) {
+	tests := []struct {
+		q, n Quantity
+		want Quantity
+	}{
+		{q: 2000, n: 1500, want: 3000},
+		{q: 250, n: 3000, want: 750},
+		// 0.001 x 0.5 = 0.0005, tepat setengah dibulatkan ke atas
+		{q: 1, n: 500, want: 1},
+		// 0.001 x 0.499 = 0.000499, dibulatkan ke bawah
+		{q: 1, n: 499, want: 0},
+		{q: -1, n: 500, want: -1},
+		{q: -2000, n: 1500, want: -3000},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.Mul(tt.n); got != tt.want {
+			t.Errorf("%s x %s = %s, mau %s", tt.q, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestQuantityMulPrice(t *testing.T) {
+	tests := []struct {
+		q     Quantity
+		price int
+		want  int
+	}{
+		{q: 2000, price: 3500, want: 7000},
+		{q: 750, price: 13500, want: 10125},
+		// 0.5 x 3 = 1.5 rupiah, tepat setengah dibulatkan ke atas
+		{q: 500, price: 3, want: 2},
+		// 0.5 x 1 = 0.5 rupiah
+		{q: 500, price: 1, want: 1},
+		// 0.333 x 1 = 0.333 rupiah
+		{q: 333, price: 1, want: 0},
+		// 1.001 x 499 = 499.499 rupiah
+		{q: 1001, price: 499, want: 499},
+		// 1.001 x 500 = 500.5 rupiah
+		{q: 1001, price: 500, want: 501},
+		// negatif menjauhi nol, -0.5 x 3 = -1.5
+		{q: -500, price: 3, want: -2},
+		{q: 500, price: -3, want: -2},
+		{q: 0, price: 13500, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.MulPrice(tt.price); got != tt.want {
+			t.Errorf("%s x Rp%d = %d, mau %d", tt.q, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestQuantityIsWhole(t *testing.T) {
+	tests := []struct {
+		q    Quantity
+		want bool
+	}{
+		{q: 0, want: true},
+		{q: 3000, want: true},
+		{q: -3000, want: true},
+		{q: 3001, want: false},
+		{q: -500, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.IsWhole(); got != tt.want {
+			t.Errorf("Quantity(%d).IsWhole() = %v, mau %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestQuantityScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Quantity
+		wantErr bool
+	}{
+		{name: "nil", src: nil, want: 0},
+		{name: "int64 SQLite INTEGER", src: int64(7), want: 7000},
+		{name: "int64 negatif", src: int64(-2), want: -2000},
+		{name: "float64 SQLite REAL", src: 25.5, want: 25500},
+		// Sisa error float dari ROUND(stock - x, 3) di SQLite
+		{name: "float64 sisa pembulatan", src: 0.30000000000000004, want: 300},
+		{name: "float64 sedikit di bawah", src: 2.9999999999999996, want: 3000},
+		{name: "float64 negatif", src: -0.75, want: -750},
+		{name: "[]byte Postgres NUMERIC", src: []byte("10.500"), want: 10500},
+		{name: "[]byte bulat", src: []byte("4"), want: 4000},
+		// SUM di NUMERIC tanpa skala bisa lebih dari 3 desimal
+		{name: "[]byte lebih dari 3 desimal", src: []byte("1.00049"), want: 1000},
+		{name: "[]byte setengah", src: []byte("1.0005"), want: 1001},
+		{name: "string", src: "0.75", want: 750},
+		{name: "string negatif", src: "-1.5", want: -1500},
+		{name: "string rusak", src: "abc", wantErr: true},
+		{name: "tipe lain", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var q Quantity
+		err := q.Scan(tt.src)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: Scan(%v) = %d, mau error", tt.name, tt.src, q)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Scan(%v) error: %v", tt.name, tt.src, err)
+			continue
+		}
+		if q != tt.want {
+			t.Errorf("%s: Scan(%v) = %d, mau %d", tt.name, tt.src, q, tt.want)
+		}
+	}
+}
+
+func TestQuantityValue(t *testing.T) {
+	v, err := Quantity(10500).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "10.5" {
+		t.Errorf("Value() = %v, mau 10.5", v)
+	}
+}
+
+func TestQuantityJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Quantity
+		wantErr bool
+	}{
+		{in: `1.5`, want: 1500},
+		{in: `"1.5"`, want: 1500},
+		{in: `2`, want: 2000},
+		{in: `-0.25`, want: -250},
+		{in: `null`, want: 0},
+		{in: `0.1234`, wantErr: true},
+		{in: `"x"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var q Quantity
+		err := json.Unmarshal([]byte(tt.in), &q)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %d, mau error", tt.in, q)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s) error: %v", tt.in, err)
+			continue
+		}
+		if q != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, mau %d", tt.in, q, tt.want)
+		}
+	}
+
+	out, err := json.Marshal(struct {
+		Stock Quantity `json:"stock"`
+	}{Stock: 750})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"stock":0.75}` {
+		t.Errorf("Marshal = %s, mau {\"stock\":0.75}", out)
+	}
+}
@@ -1,12 +1,43 @@
 package models
 
+import "math"
+
 type ProductBestSeller struct {
 	Nama       string `json:"nama"`
-	QtyTerjual int    `json:"qty_terjual"`
+	QtyTerjual Quantity `json:"qty_terjual"`
+}
+
+// ProductSales - penjualan satu produk. QtyTerjual dijual langsung,
+// QtyViaBundle terjual sebagai komponen paket. Revenue dan COGS hanya dari
+// penjualan langsung, pendapatan dan HPP paket masuk ke produk paketnya
+type ProductSales struct {
+	ProductID    int      `json:"product_id"`
+	Nama         string   `json:"nama"`
+	QtyTerjual   Quantity `json:"qty_terjual"`
+	QtyViaBundle Quantity `json:"qty_via_bundle"`
+	Revenue      int      `json:"revenue"`
+	COGS         int      `json:"cogs"`
+	GrossProfit  int      `json:"gross_profit"`
+	// GrossMargin - persen laba kotor dari revenue, 0 kalau tidak ada revenue
+	GrossMargin float64 `json:"gross_margin"`
 }
 
 type SalesReport struct {
 	TotalRevenue   int             `json:"total_revenue"`
 	TotalTransaksi int             `json:"total_transaksi"`
+	// TotalCOGS - harga pokok penjualan, GrossProfit = TotalRevenue - TotalCOGS
+	TotalCOGS   int     `json:"total_cogs"`
+	GrossProfit int     `json:"gross_profit"`
+	GrossMargin float64 `json:"gross_margin"`
 	ProdukTerlaris ProductBestSeller `json:"produk_terlaris"`
-}
\ No newline at end of file
+	// Produk - rincian per produk, urut product_id
+	Produk []ProductSales `json:"produk"`
+}
+// GrossMargin - laba kotor dalam persen revenue, dibulatkan 2 angka di
+// belakang koma. 0 kalau revenue 0
+func GrossMargin(revenue, grossProfit int) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return math.Round(float64(grossProfit)*10000/float64(revenue)) / 100
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LowStockAlert - checkout membuat stok produk turun melewati ReorderLevel
+type LowStockAlert struct {
+	ProdukID      int       `json:"produk_id"`
+	Name          string    `json:"name"`
+	Stock         Quantity  `json:"stock"`
+	ReorderLevel  Quantity  `json:"reorder_level"`
+	Unit          string    `json:"unit"`
+	TransactionID int       `json:"transaction_id"`
+	Time          time.Time `json:"time"`
+}
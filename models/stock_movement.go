@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Jenis stock movement. Sale hanya dicatat checkout, sisanya boleh lewat
+// penyesuaian stok manual
+const (
+	MovementSale       = "sale"
+	MovementRefund     = "refund"
+	MovementAdjustment = "adjustment"
+	MovementReceiving  = "receiving"
+	MovementTransfer   = "transfer"
+)
+
+// ManualMovementTypes - jenis yang boleh dikirim ke POST stock-adjustments
+var ManualMovementTypes = []string{MovementAdjustment, MovementReceiving, MovementTransfer, MovementRefund}
+
+// StockMovement - satu baris buku stok, tidak pernah diubah atau dihapus.
+// Quantity bertanda: positif menambah stok, negatif mengurangi. Stok produk
+// (atau varian kalau VariantID diisi) selalu sama dengan jumlah Quantity
+// semua movement-nya
+type StockMovement struct {
+	ID        int      `json:"id"`
+	ProdukID  int      `json:"produk_id"`
+	VariantID int      `json:"variant_id,omitempty"`
+	Type      string   `json:"type"`
+	Quantity  Quantity `json:"quantity"`
+	Reason    string   `json:"reason,omitempty"`
+	User      string   `json:"user,omitempty"`
+	// Reference - dokumen sumber, misal transaction:12 atau nomor surat jalan
+	Reference string    `json:"reference,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
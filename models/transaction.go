@@ -14,13 +14,33 @@ type TransactionDetail struct {
 	TransactionID int    `json:"transaction_id"`
 	ProductID     int    `json:"product_id"`
 	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
+	VariantID     int    `json:"variant_id,omitempty"`
+	VariantName   string `json:"variant_name,omitempty"`
+	Quantity      Quantity `json:"quantity"`
+	Unit          string   `json:"unit,omitempty"`
+	// UnitPrice - harga satuan saat transaksi, tidak ikut berubah kalau harga produk diubah
+	// Subtotal = UnitPrice x Quantity dibulatkan ke rupiah terdekat
+	UnitPrice int `json:"unit_price"`
 	Subtotal      int    `json:"subtotal"`
+	// UnitCost - harga pokok satuan saat transaksi, Cost = UnitCost x Quantity
+	// dibulatkan (HPP baris). Untuk paket dijumlah dari komponennya
+	UnitCost int `json:"unit_cost"`
+	Cost     int `json:"cost"`
+	// BundleID - diisi di baris komponen paket: produk paketnya. Baris komponen
+	// tidak punya harga (subtotal 0), pendapatan tetap di baris paket
+	BundleID int `json:"bundle_id,omitempty"`
+	// Components - baris komponen di bawah baris paket, untuk struk
+	Components []TransactionDetail `json:"components,omitempty"`
 }
 
 type CheckoutItem struct {
 	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	// Barcode - alternatif ProductID untuk hasil scan kasir
+	Barcode string `json:"barcode,omitempty"`
+	// VariantID - wajib kalau produk punya varian, ProductID boleh dikosongkan
+	VariantID int `json:"variant_id,omitempty"`
+	// Quantity - boleh pecahan untuk produk yang dijual per kg / l / m
+	Quantity Quantity `json:"quantity"`
 }
 
 type CheckoutRequest struct {
@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"kasirApi/models"
+)
+
+// LogNotifier - tulis alert sebagai satu baris JSON per alert, ke file atau
+// stdout
+type LogNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLogNotifier - alert ditulis ke w, misal os.Stdout
+func NewLogNotifier(w io.Writer) *LogNotifier {
+	return &LogNotifier{w: w}
+}
+
+// NewFileNotifier - alert ditambahkan ke akhir file path, file dibuat kalau
+// belum ada
+func NewFileNotifier(path string) (*LogNotifier, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogNotifier(f), nil
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, alert models.LowStockAlert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err = n.w.Write(append(line, '\n'))
+	return err
+}
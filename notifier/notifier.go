@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+
+	"kasirApi/models"
+)
+
+// Notifier - tujuan alert stok menipis. Implementasi lain (email, Slack, ...)
+// cukup memenuhi interface ini.
+type Notifier interface {
+	Notify(ctx context.Context, alert models.LowStockAlert) error
+}
+
+// Multi - kirim alert ke semua notifier, error dari masing-masing digabung
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, alert models.LowStockAlert) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
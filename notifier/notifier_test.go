@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"kasirApi/models"
+)
+
+func testAlert() models.LowStockAlert {
+	return models.LowStockAlert{
+		ProdukID:      3,
+		Name:          "Beras Curah",
+		Stock:         4500,
+		ReorderLevel:  5000,
+		Unit:          "kg",
+		TransactionID: 12,
+		Time:          time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestWebhookNotifierSendsJSON(t *testing.T) {
+	var (
+		method      string
+		contentType string
+		body        []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL, time.Second)
+	if err := n.Notify(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+
+	if method != http.MethodPost {
+		t.Errorf("method = %s, mau POST", method)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %s, mau application/json", contentType)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("body bukan JSON: %v (%s)", err, body)
+	}
+	want := map[string]interface{}{
+		"produk_id":      float64(3),
+		"name":           "Beras Curah",
+		"stock":          4.5,
+		"reorder_level":  float64(5),
+		"unit":           "kg",
+		"transaction_id": float64(12),
+		"time":           "2026-01-02T03:04:05Z",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, mau %v", key, got[key], value)
+		}
+	}
+}
+
+func TestWebhookNotifierNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), testAlert())
+	if err == nil {
+		t.Fatal("Notify ke server yang membalas 500 harus error")
+	}
+	if !strings.Contains(err.Error(), "500") {
+		t.Errorf("error = %v, mau menyebut status 500", err)
+	}
+}
+
+func TestWebhookNotifierTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL, 20*time.Millisecond).Notify(context.Background(), testAlert())
+	if err == nil {
+		t.Fatal("webhook yang lebih lambat dari timeout harus error")
+	}
+}
+
+func TestLogNotifierWritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewLogNotifier(&buf)
+	for i := 0; i < 2; i++ {
+		if err := n.Notify(context.Background(), testAlert()); err != nil {
+			t.Fatalf("Notify error: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("jumlah baris = %d, mau 2: %q", len(lines), buf.String())
+	}
+	var got models.LowStockAlert
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("baris bukan JSON: %v", err)
+	}
+	if got != testAlert() {
+		t.Errorf("alert = %+v, mau %+v", got, testAlert())
+	}
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, alert models.LowStockAlert) error {
+	return errors.New("gagal")
+}
+
+func TestMultiNotifiesAll(t *testing.T) {
+	var buf bytes.Buffer
+	err := Multi{failingNotifier{}, NewLogNotifier(&buf)}.Notify(context.Background(), testAlert())
+	if err == nil {
+		t.Error("error dari salah satu notifier harus dikembalikan")
+	}
+	if buf.Len() == 0 {
+		t.Error("notifier berikutnya tetap harus dipanggil walau yang sebelumnya error")
+	}
+}
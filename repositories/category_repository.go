@@ -1,22 +1,63 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"kasirApi/models"
 )
 
-type CategoryRepository struct {
+// ErrCategoryNotFound - category tidak ada, atau sudah diarsipkan untuk
+// operasi yang hanya boleh pada category aktif (update)
+var ErrCategoryNotFound = errors.New("category tidak ditemukan")
+
+type categoryRepository struct {
 	db *sql.DB
+	// readDB - replica untuk query list, sama dengan db kalau tidak ada replica
+	readDB *sql.DB
+}
+
+type CategoryRepository interface {
+	// GetAll - category aktif, includeArchived ikut ambil yang diarsipkan
+	GetAll(ctx context.Context, includeArchived bool) ([]models.Category, error)
+	Create(ctx context.Context, category *models.Category) error
+	GetByID(ctx context.Context, id int) (*models.Category, error)
+	// GetByName - cari category aktif by nama (case-insensitive), nil kalau tidak ada
+	GetByName(ctx context.Context, name string) (*models.Category, error)
+	// Update - ganti nama category aktif, ErrCategoryNotFound kalau sudah diarsipkan
+	Update(ctx context.Context, category *models.Category) error
+	// Delete - arsipkan category (soft delete), produk di dalamnya tidak berubah
+	Delete(ctx context.Context, id int) error
+	// Restore - kembalikan category yang diarsipkan, ErrNotArchived kalau tidak diarsipkan
+	Restore(ctx context.Context, id int) error
+}
+
+// NewCategoryRepository - replica boleh nil, query list lalu jalan di primary
+func NewCategoryRepository(db, replica *sql.DB) CategoryRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &categoryRepository{db: db, readDB: replica}
 }
 
-func NewCategoryRepository(db *sql.DB) *CategoryRepository {
-	return &CategoryRepository{db: db}
+func scanCategory(row rowScanner, c *models.Category) error {
+	var deletedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Name, &deletedAt); err != nil {
+		return err
+	}
+	if deletedAt.Valid {
+		c.DeletedAt = &deletedAt.Time
+	}
+	return nil
 }
 
-func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
-	query := "SELECT id, name FROM category"
-	rows, err := repo.db.Query(query)
+func (repo *categoryRepository) GetAll(ctx context.Context, includeArchived bool) ([]models.Category, error) {
+	query := "SELECT id, name, deleted_at FROM category"
+	if !includeArchived {
+		query += " WHERE deleted_at IS NULL"
+	}
+	query += " ORDER BY id"
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +66,7 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	category := make([]models.Category, 0)
 	for rows.Next() {
 		var p models.Category
-		err := rows.Scan(&p.ID, &p.Name)
+		err := scanCategory(rows, &p)
 		if err != nil {
 			return nil, err
 		}
@@ -35,20 +76,20 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 	return category, nil
 }
 
-func (repo *CategoryRepository) Create(category *models.Category) error {
-	query := "INSERT INTO category (name) VALUES ($1, $2, $3) RETURNING id"
-	err := repo.db.QueryRow(query, category.Name).Scan(&category.ID)
+func (repo *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	query := "INSERT INTO category (name) VALUES ($1) RETURNING id"
+	err := conn(ctx, repo.db).QueryRowContext(ctx, query, category.Name).Scan(&category.ID)
 	return err
 }
 
 // GetByID - ambil category by ID
-func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
-	query := "SELECT id, name FROM category WHERE id = $1"
+func (repo *categoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	query := "SELECT id, name, deleted_at FROM category WHERE id = $1"
 
 	var p models.Category
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name)
+	err := scanCategory(conn(ctx, repo.db).QueryRowContext(ctx, query, id), &p)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("category tidak ditemukan")
+		return nil, ErrCategoryNotFound
 	}
 	if err != nil {
 		return nil, err
@@ -57,9 +98,25 @@ func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
 	return &p, nil
 }
 
-func (repo *CategoryRepository) Update(category *models.Category) error {
-	query := "UPDATE category SET name = $1 WHERE id = $2"
-	result, err := repo.db.Exec(query, category.Name, category.ID)
+// GetByName - ambil category aktif by nama, nil tanpa error kalau tidak ada
+func (repo *categoryRepository) GetByName(ctx context.Context, name string) (*models.Category, error) {
+	query := "SELECT id, name, deleted_at FROM category WHERE LOWER(name) = LOWER($1) AND deleted_at IS NULL ORDER BY id LIMIT 1"
+
+	var p models.Category
+	err := scanCategory(conn(ctx, repo.db).QueryRowContext(ctx, query, name), &p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (repo *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	query := "UPDATE category SET name = $1 WHERE id = $2 AND deleted_at IS NULL"
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, category.Name, category.ID)
 	if err != nil {
 		return err
 	}
@@ -70,15 +127,15 @@ func (repo *CategoryRepository) Update(category *models.Category) error {
 	}
 
 	if rows == 0 {
-		return errors.New("category tidak ditemukan")
+		return ErrCategoryNotFound
 	}
 
 	return nil
 }
 
-func (repo *CategoryRepository) Delete(id int) error {
-	query := "DELETE FROM category WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+func (repo *categoryRepository) Delete(ctx context.Context, id int) error {
+	query := "UPDATE category SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL"
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -88,8 +145,29 @@ func (repo *CategoryRepository) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("category tidak ditemukan")
+		return ErrCategoryNotFound
 	}
 
 	return err
 }
+
+func (repo *categoryRepository) Restore(ctx context.Context, id int) error {
+	query := "UPDATE category SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		if _, err := repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrNotArchived
+	}
+
+	return nil
+}
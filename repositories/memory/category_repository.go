@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+type categoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) repositories.CategoryRepository {
+	return &categoryRepository{store: store}
+}
+
+func (repo *categoryRepository) GetAll(ctx context.Context, includeArchived bool) ([]models.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	category := make([]models.Category, 0, len(repo.store.categories))
+	for _, c := range repo.store.categories {
+		if c.DeletedAt != nil && !includeArchived {
+			continue
+		}
+		category = append(category, c)
+	}
+	sort.Slice(category, func(i, j int) bool { return category[i].ID < category[j].ID })
+
+	return category, nil
+}
+
+func (repo *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	category.ID = repo.store.nextCategoryID
+	repo.store.nextCategoryID++
+	repo.store.categories[category.ID] = *category
+
+	return nil
+}
+
+// GetByID - ambil category by ID
+func (repo *categoryRepository) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	c, ok := repo.store.categories[id]
+	if !ok {
+		return nil, repositories.ErrCategoryNotFound
+	}
+
+	return &c, nil
+}
+
+func (repo *categoryRepository) GetByName(ctx context.Context, name string) (*models.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	var found *models.Category
+	for _, c := range repo.store.categories {
+		if c.DeletedAt == nil && strings.EqualFold(c.Name, name) && (found == nil || c.ID < found.ID) {
+			found = &c
+		}
+	}
+
+	return found, nil
+}
+
+func (repo *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	current, ok := repo.store.categories[category.ID]
+	// Category yang diarsipkan tidak bisa diubah, sama dengan versi SQL
+	if !ok || current.DeletedAt != nil {
+		return repositories.ErrCategoryNotFound
+	}
+	// Hanya nama yang di-update, sama dengan versi SQL
+	current.Name = category.Name
+	repo.store.categories[category.ID] = current
+
+	return nil
+}
+
+func (repo *categoryRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	c, ok := repo.store.categories[id]
+	if !ok || c.DeletedAt != nil {
+		return repositories.ErrCategoryNotFound
+	}
+	now := time.Now()
+	c.DeletedAt = &now
+	repo.store.categories[id] = c
+
+	return nil
+}
+
+func (repo *categoryRepository) Restore(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	c, ok := repo.store.categories[id]
+	if !ok {
+		return repositories.ErrCategoryNotFound
+	}
+	if c.DeletedAt == nil {
+		return repositories.ErrNotArchived
+	}
+	c.DeletedAt = nil
+	repo.store.categories[id] = c
+
+	return nil
+}
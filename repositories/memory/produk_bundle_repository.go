@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+type produkBundleRepository struct {
+	store *Store
+}
+
+func NewProdukBundleRepository(store *Store) repositories.ProdukBundleRepository {
+	return &produkBundleRepository{store: store}
+}
+
+func (repo *produkBundleRepository) GetByBundle(ctx context.Context, bundleIDs ...int) ([]models.BundleItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	items := make([]models.BundleItem, 0)
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(bundleIDs))) {
+		items = append(items, repo.store.namedBundleItems(id)...)
+	}
+
+	return items, nil
+}
+
+func (repo *produkBundleRepository) GetByComponent(ctx context.Context, componentID int) ([]models.BundleItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	items := make([]models.BundleItem, 0)
+	for bundleID := range repo.store.bundleItems {
+		for _, item := range repo.store.namedBundleItems(bundleID) {
+			if item.ComponentID == componentID {
+				items = append(items, item)
+			}
+		}
+	}
+	slices.SortFunc(items, func(a, b models.BundleItem) int {
+		return cmp.Compare(a.BundleID, b.BundleID)
+	})
+
+	return items, nil
+}
+
+func (repo *produkBundleRepository) SetItems(ctx context.Context, bundleID int, items []models.BundleItem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	p, ok := repo.store.produk[bundleID]
+	if !ok {
+		return repositories.ErrProdukNotFound
+	}
+
+	saved := make([]models.BundleItem, len(items))
+	for i, item := range items {
+		if _, ok := repo.store.produk[item.ComponentID]; !ok {
+			return repositories.ErrProdukNotFound
+		}
+		items[i].BundleID = bundleID
+		saved[i] = models.BundleItem{BundleID: bundleID, ComponentID: item.ComponentID, Quantity: item.Quantity}
+	}
+
+	if len(saved) == 0 {
+		delete(repo.store.bundleItems, bundleID)
+	} else {
+		repo.store.bundleItems[bundleID] = saved
+	}
+
+	// Isi paket berubah = produknya berubah, ETag lama tidak berlaku lagi
+	p.Version++
+	repo.store.produk[bundleID] = p
+
+	return nil
+}
@@ -0,0 +1,422 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+type produkRepository struct {
+	store *Store
+}
+
+func NewProdukRepository(store *Store) repositories.ProdukRepository {
+	return &produkRepository{store: store}
+}
+
+func matchProduk(p models.Produk, filter models.ProdukFilter) bool {
+	if p.DeletedAt != nil && !filter.IncludeArchived {
+		return false
+	}
+	if filter.Name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(filter.Name)) {
+		return false
+	}
+	if filter.CategoryID != 0 && p.CategoryID != filter.CategoryID {
+		return false
+	}
+	if filter.MinPrice != nil && p.Price < *filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != nil && p.Price > *filter.MaxPrice {
+		return false
+	}
+	if filter.InStock && p.Stock <= 0 {
+		return false
+	}
+	if filter.LowStock && !p.IsLowStock() {
+		return false
+	}
+	return true
+}
+
+// compareProduk - urutan (kolom sort, id) yang sama dengan ORDER BY versi SQL
+func compareProduk(a, b models.Produk, sort string) int {
+	switch sort {
+	case "name":
+		if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+			return c
+		}
+	case "price":
+		if c := cmp.Compare(a.Price, b.Price); c != 0 {
+			return c
+		}
+	case "stock":
+		if c := cmp.Compare(a.Stock, b.Stock); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func (repo *produkRepository) GetAll(ctx context.Context, filter models.ProdukFilter) ([]models.Produk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !repositories.ValidProdukSort(filter.Sort) {
+		return nil, fmt.Errorf("sort %s tidak dikenal", filter.Sort)
+	}
+	sortBy := filter.Sort
+	if sortBy == "" {
+		sortBy = "id"
+	}
+
+	// Cursor memang dibuat untuk produk terakhir halaman sebelumnya, jadi
+	// cukup cari posisi produk dengan urutan yang sama
+	var after *models.Produk
+	if filter.Cursor != "" {
+		last, err := repositories.DecodeProdukCursorPosition(filter)
+		if err != nil {
+			return nil, err
+		}
+		after = last
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	produk := make([]models.Produk, 0, len(repo.store.produk))
+	for _, p := range repo.store.produk {
+		if !matchProduk(p, filter) {
+			continue
+		}
+		if after != nil {
+			c := compareProduk(p, *after, sortBy)
+			if (!filter.Desc && c <= 0) || (filter.Desc && c >= 0) {
+				continue
+			}
+		}
+		produk = append(produk, p)
+	}
+	slices.SortFunc(produk, func(a, b models.Produk) int {
+		if filter.Desc {
+			return compareProduk(b, a, sortBy)
+		}
+		return compareProduk(a, b, sortBy)
+	})
+
+	if after == nil && filter.Offset > 0 {
+		if filter.Offset >= len(produk) {
+			return []models.Produk{}, nil
+		}
+		produk = produk[filter.Offset:]
+	}
+	if filter.Limit > 0 && len(produk) > filter.Limit {
+		produk = produk[:filter.Limit]
+	}
+
+	return produk, nil
+}
+
+func (repo *produkRepository) Count(ctx context.Context, filter models.ProdukFilter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	total := 0
+	for _, p := range repo.store.produk {
+		if matchProduk(p, filter) {
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+func (repo *produkRepository) Create(ctx context.Context, produk *models.Produk) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	if err := repo.store.checkCodes(produk); err != nil {
+		return err
+	}
+
+	produk.ID = repo.store.nextProdukID
+	repo.store.nextProdukID++
+	produk.Version = 1
+	if produk.Barcodes != nil {
+		produk.Barcodes = slices.Compact(slices.Sorted(slices.Values(produk.Barcodes)))
+	}
+	produk.Category = nil
+	produk.Variants = nil
+	produk.DeletedAt = nil
+	produk.ImageKey = ""
+	produk.ImageURL, produk.ThumbnailURL = "", ""
+	repo.store.produk[produk.ID] = *produk
+	repo.store.recordPrice(produk.ID, 0, produk.Price)
+	repo.store.recordStockChange(produk.ID, 0, 0, produk.Stock, "stok awal")
+
+	return nil
+}
+
+// GetByID - ambil produk by ID
+func (repo *produkRepository) GetByID(ctx context.Context, id int) (*models.Produk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	p, ok := repo.store.produk[id]
+	if !ok {
+		return nil, repositories.ErrProdukNotFound
+	}
+
+	return &p, nil
+}
+
+func (repo *produkRepository) GetByName(ctx context.Context, name string) (*models.Produk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	var found *models.Produk
+	for _, p := range repo.store.produk {
+		if p.DeletedAt == nil && strings.EqualFold(p.Name, name) && (found == nil || p.ID < found.ID) {
+			found = &p
+		}
+	}
+
+	return found, nil
+}
+
+func (repo *produkRepository) GetByBarcode(ctx context.Context, code string) (*models.Produk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	p, ok := repo.store.findByCode(code)
+	if !ok {
+		return nil, fmt.Errorf("produk dengan barcode %s tidak ditemukan", code)
+	}
+
+	return &p, nil
+}
+
+func (repo *produkRepository) Update(ctx context.Context, produk *models.Produk) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	current, ok := repo.store.produk[produk.ID]
+	// Produk yang diarsipkan tidak bisa diubah, sama dengan versi SQL
+	if !ok || current.DeletedAt != nil {
+		return repositories.ErrProdukNotFound
+	}
+	if produk.Version > 0 && produk.Version != current.Version {
+		return repositories.ErrVersionConflict
+	}
+	if err := repo.store.checkCodes(produk); err != nil {
+		return err
+	}
+
+	// Hanya kolom yang juga di-update versi SQL
+	current.Name = produk.Name
+	current.Price = produk.Price
+	current.CostPrice = produk.CostPrice
+	before := current.Stock
+	current.Stock = produk.Stock
+	current.ReorderLevel = produk.ReorderLevel
+	current.Unit = produk.Unit
+	current.CategoryID = produk.CategoryID
+	current.SKU = produk.SKU
+	if produk.Barcodes != nil {
+		current.Barcodes = slices.Compact(slices.Sorted(slices.Values(produk.Barcodes)))
+	}
+	current.Version++
+	repo.store.produk[produk.ID] = current
+	repo.store.recordPrice(produk.ID, 0, current.Price)
+	repo.store.recordStockChange(produk.ID, 0, before, current.Stock, "stok diubah lewat update produk")
+
+	produk.Version = current.Version
+	produk.Barcodes = slices.Clone(current.Barcodes)
+	produk.DeletedAt = current.DeletedAt
+	produk.ImageKey = current.ImageKey
+
+	return nil
+}
+
+func (repo *produkRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	p, ok := repo.store.produk[id]
+	if !ok || p.DeletedAt != nil {
+		return repositories.ErrProdukNotFound
+	}
+	now := time.Now()
+	p.DeletedAt = &now
+	p.Version++
+	repo.store.produk[id] = p
+
+	return nil
+}
+
+func (repo *produkRepository) SetImage(ctx context.Context, id int, imageKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	p, ok := repo.store.produk[id]
+	if !ok || p.DeletedAt != nil {
+		return repositories.ErrProdukNotFound
+	}
+	p.ImageKey = imageKey
+	p.Version++
+	repo.store.produk[id] = p
+
+	return nil
+}
+
+func (repo *produkRepository) Restore(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	p, ok := repo.store.produk[id]
+	if !ok {
+		return repositories.ErrProdukNotFound
+	}
+	if p.DeletedAt == nil {
+		return repositories.ErrNotArchived
+	}
+	p.DeletedAt = nil
+	p.Version++
+	repo.store.produk[id] = p
+
+	return nil
+}
+
+func (repo *produkRepository) GetPriceHistory(ctx context.Context, produkID int) ([]models.PriceHistory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	// Terbaru di atas, sama dengan ORDER BY effective_from DESC versi SQL
+	history := make([]models.PriceHistory, 0)
+	for i := len(repo.store.priceHistory) - 1; i >= 0; i-- {
+		if h := repo.store.priceHistory[i]; h.ProdukID == produkID {
+			history = append(history, h)
+		}
+	}
+
+	return history, nil
+}
+
+func (repo *produkRepository) GetStockMovements(ctx context.Context, produkID int) ([]models.StockMovement, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	// Terbaru di atas, sama dengan ORDER BY id DESC versi SQL
+	movements := make([]models.StockMovement, 0)
+	for i := len(repo.store.stockMovements) - 1; i >= 0; i-- {
+		if m := repo.store.stockMovements[i]; m.ProdukID == produkID {
+			movements = append(movements, m)
+		}
+	}
+
+	return movements, nil
+}
+
+func (repo *produkRepository) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	if movement.VariantID != 0 {
+		variant, ok := repo.store.variants[movement.VariantID]
+		if !ok || variant.DeletedAt != nil || variant.ProdukID != movement.ProdukID {
+			return errors.New("varian tidak ditemukan")
+		}
+		if variant.Stock+movement.Quantity < 0 {
+			return repositories.ErrNegativeStock
+		}
+		variant.Stock += movement.Quantity
+		repo.store.variants[variant.ID] = variant
+	} else {
+		produk, ok := repo.store.produk[movement.ProdukID]
+		if !ok {
+			return repositories.ErrProdukNotFound
+		}
+		if produk.Stock+movement.Quantity < 0 {
+			return repositories.ErrNegativeStock
+		}
+		produk.Stock += movement.Quantity
+		produk.Version++
+		repo.store.produk[produk.ID] = produk
+	}
+
+	if stored, ok := repo.store.recordMovement(*movement); ok {
+		movement.ID, movement.CreatedAt = stored.ID, stored.CreatedAt
+	}
+	return nil
+}
+
+func (repo *produkRepository) Search(ctx context.Context, query string, limit int) ([]models.ProdukSearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	produk := make([]models.Produk, 0, len(repo.store.produk))
+	for _, p := range repo.store.produk {
+		if p.DeletedAt == nil {
+			produk = append(produk, p)
+		}
+	}
+	categories := make(map[int]string, len(repo.store.categories))
+	for id, c := range repo.store.categories {
+		categories[id] = c.Name
+	}
+
+	return repositories.RankProduk(query, produk, categories, limit), nil
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+type produkVariantRepository struct {
+	store *Store
+}
+
+func NewProdukVariantRepository(store *Store) repositories.ProdukVariantRepository {
+	return &produkVariantRepository{store: store}
+}
+
+func (repo *produkVariantRepository) GetByProduk(ctx context.Context, produkIDs ...int) ([]models.ProdukVariant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	variants := make([]models.ProdukVariant, 0)
+	for _, v := range repo.store.variants {
+		if v.DeletedAt == nil && slices.Contains(produkIDs, v.ProdukID) {
+			variants = append(variants, v)
+		}
+	}
+	slices.SortFunc(variants, func(a, b models.ProdukVariant) int {
+		if c := cmp.Compare(a.ProdukID, b.ProdukID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return variants, nil
+}
+
+func (repo *produkVariantRepository) GetByID(ctx context.Context, id int) (*models.ProdukVariant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	v, ok := repo.store.variants[id]
+	if !ok || v.DeletedAt != nil {
+		return nil, errors.New("varian tidak ditemukan")
+	}
+
+	return &v, nil
+}
+
+func (repo *produkVariantRepository) Create(ctx context.Context, variant *models.ProdukVariant) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	// Pengganti FK produk_variants.produk_id
+	if _, ok := repo.store.produk[variant.ProdukID]; !ok {
+		return repositories.ErrProdukNotFound
+	}
+
+	variant.ID = repo.store.nextVariantID
+	repo.store.nextVariantID++
+	repo.store.variants[variant.ID] = *variant
+	repo.store.recordPrice(variant.ProdukID, variant.ID, variant.Price)
+	repo.store.recordStockChange(variant.ProdukID, variant.ID, 0, variant.Stock, "stok awal")
+
+	return nil
+}
+
+func (repo *produkVariantRepository) Update(ctx context.Context, variant *models.ProdukVariant) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	current, ok := repo.store.variants[variant.ID]
+	if !ok || current.DeletedAt != nil {
+		return errors.New("varian tidak ditemukan")
+	}
+
+	// produk_id tidak ikut diubah, sama dengan versi SQL
+	current.Name = variant.Name
+	current.Price = variant.Price
+	current.CostPrice = variant.CostPrice
+	before := current.Stock
+	current.Stock = variant.Stock
+	repo.store.variants[variant.ID] = current
+	repo.store.recordPrice(current.ProdukID, current.ID, current.Price)
+	repo.store.recordStockChange(current.ProdukID, current.ID, before, current.Stock, "stok diubah lewat update varian")
+
+	return nil
+}
+
+func (repo *produkVariantRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	variant, ok := repo.store.variants[id]
+	if !ok || variant.DeletedAt != nil {
+		return errors.New("varian tidak ditemukan")
+	}
+	// Soft delete, riwayat harga varian tetap ada sama seperti versi SQL.
+	// Sisa stok dinolkan dan dicatat ke buku stok
+	before := variant.Stock
+	now := time.Now()
+	variant.Stock = 0
+	variant.DeletedAt = &now
+	repo.store.variants[id] = variant
+	repo.store.recordStockChange(variant.ProdukID, id, before, 0, "varian dihapus")
+
+	return nil
+}
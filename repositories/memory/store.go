@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+// Store - penyimpanan in-memory yang dipakai bersama oleh semua repository memory.
+// Satu mutex untuk semua tabel supaya checkout (baca produk + kurangi stok +
+// simpan transaksi) tetap atomic seperti transaksi database.
+type Store struct {
+	mu sync.RWMutex
+	// txMu - dipegang selama UnitOfWork berjalan, tulis di luar UnitOfWork
+	// menunggu sampai commit/rollback selesai
+	txMu sync.Mutex
+
+	categories     map[int]models.Category
+	nextCategoryID int
+
+	produk       map[int]models.Produk
+	nextProdukID int
+
+	variants      map[int]models.ProdukVariant
+	nextVariantID int
+
+	// bundleItems - komponen paket per bundle_id, urut sesuai input
+	bundleItems map[int][]models.BundleItem
+
+	// priceHistory - append-only, urut sesuai waktu dicatat
+	priceHistory       []models.PriceHistory
+	nextPriceHistoryID int
+
+	// stockMovements - buku stok, append-only seperti priceHistory
+	stockMovements      []models.StockMovement
+	nextStockMovementID int
+
+	transactions      map[int]models.Transaction
+	nextTransactionID int
+	nextDetailID      int
+}
+
+func NewStore() *Store {
+	return &Store{
+		categories:          map[int]models.Category{},
+		nextCategoryID:      1,
+		produk:              map[int]models.Produk{},
+		nextProdukID:        1,
+		variants:            map[int]models.ProdukVariant{},
+		nextVariantID:       1,
+		bundleItems:         map[int][]models.BundleItem{},
+		nextPriceHistoryID:  1,
+		nextStockMovementID: 1,
+		transactions:        map[int]models.Transaction{},
+		nextTransactionID:   1,
+		nextDetailID:        1,
+	}
+}
+
+// findByCode - produk aktif dari barcode, fallback ke SKU. Pemanggil memegang lock
+func (s *Store) findByCode(code string) (models.Produk, bool) {
+	for _, p := range s.produk {
+		if p.DeletedAt == nil && slices.Contains(p.Barcodes, code) {
+			return p, true
+		}
+	}
+	for _, p := range s.produk {
+		if p.DeletedAt == nil && p.SKU != "" && p.SKU == code {
+			return p, true
+		}
+	}
+	return models.Produk{}, false
+}
+
+// checkCodes - SKU dan barcode tidak boleh dipakai produk lain. Pemanggil memegang lock
+func (s *Store) checkCodes(produk *models.Produk) error {
+	for _, p := range s.produk {
+		if p.ID == produk.ID {
+			continue
+		}
+		if produk.SKU != "" && p.SKU == produk.SKU {
+			return fmt.Errorf("%w: SKU %s", repositories.ErrDuplicateCode, produk.SKU)
+		}
+		for _, barcode := range produk.Barcodes {
+			if slices.Contains(p.Barcodes, barcode) {
+				return fmt.Errorf("%w: barcode %s", repositories.ErrDuplicateCode, barcode)
+			}
+		}
+	}
+	return nil
+}
+
+// hasVariants - produk punya minimal satu varian aktif. Pemanggil memegang lock
+func (s *Store) hasVariants(produkID int) bool {
+	for _, v := range s.variants {
+		if v.ProdukID == produkID && v.DeletedAt == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// namedBundleItems - komponen paket beserta nama produk komponennya saat ini.
+// Pemanggil memegang lock
+func (s *Store) namedBundleItems(bundleID int) []models.BundleItem {
+	items := slices.Clone(s.bundleItems[bundleID])
+	for i := range items {
+		items[i].ComponentName = s.produk[items[i].ComponentID].Name
+	}
+	return items
+}
+
+// recordPrice - catat harga baru ke riwayat kalau beda dengan harga terakhir.
+// variantID 0 artinya harga produk induk. Pemanggil memegang lock
+func (s *Store) recordPrice(produkID, variantID, price int) {
+	for i := len(s.priceHistory) - 1; i >= 0; i-- {
+		h := s.priceHistory[i]
+		if h.ProdukID == produkID && h.VariantID == variantID {
+			if h.Price == price {
+				return
+			}
+			break
+		}
+	}
+
+	s.priceHistory = append(s.priceHistory, models.PriceHistory{
+		ID:            s.nextPriceHistoryID,
+		ProdukID:      produkID,
+		VariantID:     variantID,
+		Price:         price,
+		EffectiveFrom: time.Now(),
+	})
+	s.nextPriceHistoryID++
+}
+
+// recordMovement - tambah satu baris buku stok dan kembalikan baris yang
+// disimpan. Quantity 0 dilewati, ok false. Pemanggil memegang lock
+func (s *Store) recordMovement(movement models.StockMovement) (stored models.StockMovement, ok bool) {
+	if movement.Quantity == 0 {
+		return movement, false
+	}
+
+	movement.ID = s.nextStockMovementID
+	movement.CreatedAt = time.Now()
+	s.stockMovements = append(s.stockMovements, movement)
+	s.nextStockMovementID++
+	return movement, true
+}
+
+// recordStockChange - catat selisih stok yang ditimpa langsung lewat
+// create / update produk atau varian sebagai adjustment. Pemanggil memegang lock
+func (s *Store) recordStockChange(produkID, variantID int, before, after models.Quantity, reason string) {
+	s.recordMovement(models.StockMovement{
+		ProdukID:  produkID,
+		VariantID: variantID,
+		Type:      models.MovementAdjustment,
+		Quantity:  after - before,
+		Reason:    reason,
+	})
+}
+
+type txKey struct{}
+
+func (s *Store) inTx(ctx context.Context) bool {
+	store, _ := ctx.Value(txKey{}).(*Store)
+	return store == s
+}
+
+// lock - kunci untuk operasi tulis. Di luar UnitOfWork juga ikut antri di txMu
+func (s *Store) lock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		s.mu.Lock()
+		return s.mu.Unlock
+	}
+
+	s.txMu.Lock()
+	s.mu.Lock()
+	return func() {
+		s.mu.Unlock()
+		s.txMu.Unlock()
+	}
+}
+
+// snapshot - salinan semua tabel, dipakai untuk rollback UnitOfWork
+func (s *Store) snapshot() *Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &Store{
+		categories:          maps.Clone(s.categories),
+		nextCategoryID:      s.nextCategoryID,
+		produk:              maps.Clone(s.produk),
+		nextProdukID:        s.nextProdukID,
+		variants:            maps.Clone(s.variants),
+		nextVariantID:       s.nextVariantID,
+		bundleItems:         maps.Clone(s.bundleItems),
+		priceHistory:        slices.Clone(s.priceHistory),
+		nextPriceHistoryID:  s.nextPriceHistoryID,
+		stockMovements:      slices.Clone(s.stockMovements),
+		nextStockMovementID: s.nextStockMovementID,
+		transactions:        maps.Clone(s.transactions),
+		nextTransactionID:   s.nextTransactionID,
+		nextDetailID:        s.nextDetailID,
+	}
+}
+
+func (s *Store) restore(snap *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.categories = snap.categories
+	s.nextCategoryID = snap.nextCategoryID
+	s.produk = snap.produk
+	s.nextProdukID = snap.nextProdukID
+	s.variants = snap.variants
+	s.nextVariantID = snap.nextVariantID
+	s.bundleItems = snap.bundleItems
+	s.priceHistory = snap.priceHistory
+	s.nextPriceHistoryID = snap.nextPriceHistoryID
+	s.stockMovements = snap.stockMovements
+	s.nextStockMovementID = snap.nextStockMovementID
+	s.transactions = snap.transactions
+	s.nextTransactionID = snap.nextTransactionID
+	s.nextDetailID = snap.nextDetailID
+}
@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+// Format tanggal yang dikirim handler report, sama dengan yang dipakai query SQL
+const reportTimeLayout = "2006-01-02 15:04:05"
+
+type transactionRepository struct {
+	store *Store
+}
+
+func NewTransactionRepository(store *Store) repositories.TransactionRepository {
+	return &transactionRepository{store: store}
+}
+
+func (repo *transactionRepository) CreateTransaction(ctx context.Context, items []models.CheckoutItem) (*models.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	// Validasi dan hitung di salinan stok dulu, baru ditulis kalau semua item lolos
+	stock := map[int]models.Quantity{}
+	variantStock := map[int]models.Quantity{}
+	totalAmount := 0
+	details := make([]models.TransactionDetail, 0, len(items))
+
+	for _, item := range items {
+		// Hasil scan kasir: cari product_id dari barcode / SKU
+		if item.ProductID == 0 && item.Barcode != "" {
+			p, ok := repo.store.findByCode(item.Barcode)
+			if !ok {
+				return nil, fmt.Errorf("produk dengan barcode %s tidak ditemukan", item.Barcode)
+			}
+			item.ProductID = p.ID
+		}
+
+		// Varian punya harga dan stok sendiri, produk induknya diambil dari varian
+		var variant models.ProdukVariant
+		if item.VariantID != 0 {
+			// Varian yang sudah dihapus tidak bisa dijual lagi
+			v, ok := repo.store.variants[item.VariantID]
+			if !ok || v.DeletedAt != nil {
+				return nil, fmt.Errorf("variant id %d not found", item.VariantID)
+			}
+			if item.ProductID != 0 && item.ProductID != v.ProdukID {
+				return nil, fmt.Errorf("variant id %d bukan varian product id %d", item.VariantID, item.ProductID)
+			}
+			item.ProductID = v.ProdukID
+			variant = v
+		}
+
+		// Produk yang diarsipkan tidak bisa dijual lagi
+		p, ok := repo.store.produk[item.ProductID]
+		if !ok || p.DeletedAt != nil {
+			return nil, fmt.Errorf("product id %d not found", item.ProductID)
+		}
+
+		if err := repositories.ValidateQuantity(p.Name, p.Unit, item.Quantity); err != nil {
+			return nil, err
+		}
+
+		price, unitCost := p.Price, p.CostPrice
+		var components []models.TransactionDetail
+		if item.VariantID != 0 {
+			current, seen := variantStock[variant.ID]
+			if !seen {
+				current = variant.Stock
+			}
+			if current < item.Quantity {
+				return nil, fmt.Errorf("stok kurang for product %s (%s)", p.Name, variant.Name)
+			}
+			variantStock[variant.ID] = current - item.Quantity
+			price, unitCost = variant.Price, variant.CostPrice
+		} else {
+			if repo.store.hasVariants(p.ID) {
+				return nil, fmt.Errorf("product %s punya varian, variant_id wajib diisi", p.Name)
+			}
+			// Paket tidak punya stok sendiri, yang dicek dan dikurangi stok komponennya.
+			// HPP paket dijumlah dari komponennya
+			bundleCost := 0
+			for _, bundleItem := range repo.store.bundleItems[p.ID] {
+				component := repo.store.produk[bundleItem.ComponentID]
+				if component.DeletedAt != nil {
+					return nil, fmt.Errorf("komponen %s dari paket %s sudah diarsipkan", component.Name, p.Name)
+				}
+
+				need := item.Quantity.Mul(bundleItem.Quantity)
+				current, seen := stock[component.ID]
+				if !seen {
+					current = component.Stock
+				}
+				if current < need {
+					return nil, fmt.Errorf("stok kurang for product %s (komponen %s)", p.Name, component.Name)
+				}
+				stock[component.ID] = current - need
+				bundleCost += bundleItem.Quantity.MulPrice(component.CostPrice)
+
+				components = append(components, models.TransactionDetail{
+					ProductID:   component.ID,
+					ProductName: component.Name,
+					Quantity:    need,
+					Unit:        component.Unit,
+					UnitCost:    component.CostPrice,
+					Cost:        need.MulPrice(component.CostPrice),
+					BundleID:    p.ID,
+				})
+			}
+
+			if components != nil {
+				unitCost = bundleCost
+			} else {
+				current, seen := stock[p.ID]
+				if !seen {
+					current = p.Stock
+				}
+				if current < item.Quantity {
+					return nil, fmt.Errorf("stok kurang for product %s", p.Name)
+				}
+				stock[p.ID] = current - item.Quantity
+			}
+		}
+
+		subtotal := item.Quantity.MulPrice(price)
+		totalAmount += subtotal
+
+		cost := item.Quantity.MulPrice(unitCost)
+		if components != nil {
+			// HPP paket = HPP stok komponen yang benar-benar keluar
+			cost = 0
+			for _, c := range components {
+				cost += c.Cost
+			}
+		}
+
+		details = append(details, models.TransactionDetail{
+			ProductID:   item.ProductID,
+			ProductName: p.Name,
+			VariantID:   variant.ID,
+			VariantName: variant.Name,
+			Quantity:    item.Quantity,
+			Unit:        p.Unit,
+			UnitPrice:   price,
+			Subtotal:    subtotal,
+			UnitCost:    unitCost,
+			Cost:        cost,
+			Components:  components,
+		})
+	}
+
+	for id, s := range stock {
+		p := repo.store.produk[id]
+		p.Stock = s
+		p.Version++
+		repo.store.produk[id] = p
+	}
+	for id, s := range variantStock {
+		v := repo.store.variants[id]
+		v.Stock = s
+		repo.store.variants[id] = v
+	}
+
+	transaction := models.Transaction{
+		ID:          repo.store.nextTransactionID,
+		TotalAmount: totalAmount,
+		CreatedAt:   time.Now(),
+	}
+	repo.store.nextTransactionID++
+
+	// Nomor baris sama dengan versi SQL: komponen tepat setelah paketnya
+	for i := range details {
+		details[i].ID = repo.store.nextDetailID
+		details[i].TransactionID = transaction.ID
+		repo.store.nextDetailID++
+		for j := range details[i].Components {
+			details[i].Components[j].ID = repo.store.nextDetailID
+			details[i].Components[j].TransactionID = transaction.ID
+			repo.store.nextDetailID++
+		}
+	}
+	transaction.Details = details
+	repo.store.transactions[transaction.ID] = transaction
+	for _, m := range repositories.SaleMovements(transaction.ID, details) {
+		repo.store.recordMovement(m)
+	}
+
+	result := transaction
+	result.Details = append([]models.TransactionDetail(nil), details...)
+
+	return &result, nil
+}
+
+func (repo *transactionRepository) GetSalesReport(ctx context.Context, startDate, endDate string) (*models.SalesReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start, err := time.ParseInLocation(reportTimeLayout, startDate, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.ParseInLocation(reportTimeLayout, endDate, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.store.mu.RLock()
+	defer repo.store.mu.RUnlock()
+
+	var report models.SalesReport
+	qtyByName := map[string]models.Quantity{}
+	salesByID := map[int]*models.ProductSales{}
+	// Versi SQL join ke produk, jadi pakai nama produk saat ini
+	nameOf := func(d models.TransactionDetail) string {
+		if p, ok := repo.store.produk[d.ProductID]; ok {
+			return p.Name
+		}
+		return d.ProductName
+	}
+	sales := func(d models.TransactionDetail) *models.ProductSales {
+		s, ok := salesByID[d.ProductID]
+		if !ok {
+			s = &models.ProductSales{ProductID: d.ProductID, Nama: nameOf(d)}
+			salesByID[d.ProductID] = s
+		}
+		return s
+	}
+
+	for _, t := range repo.store.transactions {
+		if t.CreatedAt.Before(start) || t.CreatedAt.After(end) {
+			continue
+		}
+		report.TotalRevenue += t.TotalAmount
+		report.TotalTransaksi++
+
+		for _, d := range t.Details {
+			// Baris komponen paket tidak ikut produk terlaris, yang dihitung paketnya
+			qtyByName[nameOf(d)] += d.Quantity
+			s := sales(d)
+			s.QtyTerjual += d.Quantity
+			s.Revenue += d.Subtotal
+			s.COGS += d.Cost
+			report.TotalCOGS += d.Cost
+
+			for _, c := range d.Components {
+				sales(c).QtyViaBundle += c.Quantity
+			}
+		}
+	}
+
+	report.ProdukTerlaris = models.ProductBestSeller{Nama: "-", QtyTerjual: 0}
+	for name, qty := range qtyByName {
+		best := report.ProdukTerlaris
+		if best.Nama == "-" || qty > best.QtyTerjual || (qty == best.QtyTerjual && name < best.Nama) {
+			report.ProdukTerlaris = models.ProductBestSeller{Nama: name, QtyTerjual: qty}
+		}
+	}
+
+	report.Produk = make([]models.ProductSales, 0, len(salesByID))
+	for _, s := range salesByID {
+		report.Produk = append(report.Produk, *s)
+	}
+	slices.SortFunc(report.Produk, func(a, b models.ProductSales) int {
+		return cmp.Compare(a.ProductID, b.ProductID)
+	})
+
+	return &report, nil
+}
+
+func (repo *transactionRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	defer repo.store.lock(ctx)()
+
+	if _, ok := repo.store.transactions[id]; !ok {
+		return errors.New("transaction tidak ditemukan")
+	}
+	delete(repo.store.transactions, id)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+
+	"kasirApi/repositories"
+)
+
+type unitOfWork struct {
+	store *Store
+}
+
+// NewUnitOfWork - UnitOfWork untuk Store in-memory. Satu UnitOfWork berjalan
+// pada satu waktu, rollback mengembalikan semua tabel ke kondisi sebelum Do.
+// Pembacaan di luar UnitOfWork bisa melihat data yang belum di-commit.
+func NewUnitOfWork(store *Store) repositories.UnitOfWork {
+	return &unitOfWork{store: store}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if u.store.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	u.store.txMu.Lock()
+	defer u.store.txMu.Unlock()
+
+	snap := u.store.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			u.store.restore(snap)
+			panic(p)
+		}
+		if err != nil {
+			u.store.restore(snap)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, u.store))
+}
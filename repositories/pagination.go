@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"kasirApi/models"
+)
+
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// produkSortColumns - kolom yang boleh dipakai untuk sort produk
+var produkSortColumns = map[string]string{
+	"id":    "id",
+	"name":  "LOWER(name)",
+	"price": "price",
+	"stock": "stock",
+}
+
+// ValidProdukSort - true kalau field sort dikenal
+func ValidProdukSort(sort string) bool {
+	_, ok := produkSortColumns[normalizeSort(sort)]
+	return ok
+}
+
+func normalizeSort(sort string) string {
+	if sort == "" {
+		return "id"
+	}
+	return sort
+}
+
+// produkCursor - posisi baris terakhir untuk keyset pagination,
+// urutannya selalu (kolom sort, id)
+type produkCursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	ID   int    `json:"id"`
+	Num  int    `json:"n,omitempty"`
+	// Qty - nilai stok, terpisah dari Num karena bisa pecahan
+	Qty  models.Quantity `json:"q,omitempty"`
+	Text string          `json:"t,omitempty"`
+}
+
+// EncodeProdukCursor - cursor untuk halaman setelah produk last
+func EncodeProdukCursor(filter models.ProdukFilter, last models.Produk) string {
+	c := produkCursor{Sort: normalizeSort(filter.Sort), Desc: filter.Desc, ID: last.ID}
+	switch c.Sort {
+	case "name":
+		c.Text = strings.ToLower(last.Name)
+	case "price":
+		c.Num = last.Price
+	case "stock":
+		c.Qty = last.Stock
+	}
+
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeProdukCursor - nil kalau filter tidak pakai cursor. Cursor dari
+// urutan lain ditolak supaya halaman tidak loncat
+func decodeProdukCursor(filter models.ProdukFilter) (*produkCursor, error) {
+	if filter.Cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c produkCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != normalizeSort(filter.Sort) || c.Desc != filter.Desc {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// sortValue - nilai pembanding untuk cursor, string untuk name, Quantity untuk stock selain itu int
+func (c *produkCursor) sortValue() interface{} {
+	switch c.Sort {
+	case "name":
+		return c.Text
+	case "price":
+		return c.Num
+	case "stock":
+		return c.Qty
+	}
+	return c.ID
+}
+
+// DecodeProdukCursorPosition - cursor sebagai produk "semu" berisi nilai sort
+// dan id baris terakhir, untuk implementasi yang membandingkan di memory
+func DecodeProdukCursorPosition(filter models.ProdukFilter) (*models.Produk, error) {
+	c, err := decodeProdukCursor(filter)
+	if err != nil || c == nil {
+		return nil, err
+	}
+
+	return &models.Produk{ID: c.ID, Name: c.Text, Price: c.Num, Stock: c.Qty}, nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"kasirApi/models"
+)
+
+// GetByBarcode - cari produk dari barcode, kalau tidak ada coba cocokkan SKU
+func (repo *produkRepository) GetByBarcode(ctx context.Context, code string) (*models.Produk, error) {
+	id, err := findProdukIDByCode(ctx, conn(ctx, repo.db), code)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(ctx, id)
+}
+
+// findProdukIDByCode - id produk aktif dari barcode, fallback ke SKU
+func findProdukIDByCode(ctx context.Context, db dbtx, code string) (int, error) {
+	var id int
+	err := db.QueryRowContext(ctx, "SELECT b.produk_id FROM produk_barcodes b JOIN produk p ON p.id = b.produk_id WHERE b.barcode = $1 AND p.deleted_at IS NULL", code).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = db.QueryRowContext(ctx, "SELECT id FROM produk WHERE sku = $1 AND deleted_at IS NULL", code).Scan(&id)
+	}
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("produk dengan barcode %s tidak ditemukan", code)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// checkCodes - pastikan SKU dan barcode belum dipakai produk lain
+func (repo *produkRepository) checkCodes(ctx context.Context, produk *models.Produk) error {
+	db := conn(ctx, repo.db)
+
+	if produk.SKU != "" {
+		var id int
+		err := db.QueryRowContext(ctx, "SELECT id FROM produk WHERE sku = $1 AND id <> $2", produk.SKU, produk.ID).Scan(&id)
+		if err == nil {
+			return fmt.Errorf("%w: SKU %s", ErrDuplicateCode, produk.SKU)
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	for _, barcode := range produk.Barcodes {
+		var id int
+		err := db.QueryRowContext(ctx, "SELECT produk_id FROM produk_barcodes WHERE barcode = $1 AND produk_id <> $2", barcode, produk.ID).Scan(&id)
+		if err == nil {
+			return fmt.Errorf("%w: barcode %s", ErrDuplicateCode, barcode)
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveBarcodes - ganti semua barcode produk. Barcodes nil artinya tidak diubah,
+// slice kosong artinya hapus semua barcode
+func (repo *produkRepository) saveBarcodes(ctx context.Context, produk *models.Produk) error {
+	if produk.Barcodes == nil {
+		return repo.loadBarcodesInto(ctx, produk)
+	}
+
+	db := conn(ctx, repo.db)
+	if _, err := db.ExecContext(ctx, "DELETE FROM produk_barcodes WHERE produk_id = $1", produk.ID); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, barcode := range produk.Barcodes {
+		if seen[barcode] {
+			continue
+		}
+		seen[barcode] = true
+		if _, err := db.ExecContext(ctx, "INSERT INTO produk_barcodes (barcode, produk_id) VALUES ($1, $2)", barcode, produk.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *produkRepository) withBarcodes(ctx context.Context, produk *models.Produk) (*models.Produk, error) {
+	if err := repo.loadBarcodesInto(ctx, produk); err != nil {
+		return nil, err
+	}
+	return produk, nil
+}
+
+func (repo *produkRepository) loadBarcodesInto(ctx context.Context, produk *models.Produk) error {
+	list := []models.Produk{*produk}
+	if err := repo.loadBarcodes(ctx, repo.db, list); err != nil {
+		return err
+	}
+	produk.Barcodes = list[0].Barcodes
+	return nil
+}
+
+// loadBarcodes - isi Barcodes untuk banyak produk sekaligus dengan satu query
+func (repo *produkRepository) loadBarcodes(ctx context.Context, db *sql.DB, produk []models.Produk) error {
+	if len(produk) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(produk))
+	placeholders := make([]string, len(produk))
+	args := make([]interface{}, len(produk))
+	for i, p := range produk {
+		index[p.ID] = i
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = p.ID
+	}
+
+	query := "SELECT produk_id, barcode FROM produk_barcodes WHERE produk_id IN (" + strings.Join(placeholders, ", ") + ") ORDER BY barcode"
+	rows, err := conn(ctx, db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var produkID int
+		var barcode string
+		if err := rows.Scan(&produkID, &barcode); err != nil {
+			return err
+		}
+		i := index[produkID]
+		produk[i].Barcodes = append(produk[i].Barcodes, barcode)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"kasirApi/models"
+)
+
+type produkBundleRepository struct {
+	db *sql.DB
+	// readDB - replica untuk query list, sama dengan db kalau tidak ada replica
+	readDB *sql.DB
+}
+
+type ProdukBundleRepository interface {
+	// GetByBundle - komponen paket-paket tersebut, urut paket lalu urutan input
+	GetByBundle(ctx context.Context, bundleIDs ...int) ([]models.BundleItem, error)
+	// GetByComponent - paket yang memakai produk ini sebagai komponen
+	GetByComponent(ctx context.Context, componentID int) ([]models.BundleItem, error)
+	// SetItems - ganti semua komponen paket, items kosong = bukan paket lagi
+	SetItems(ctx context.Context, bundleID int, items []models.BundleItem) error
+}
+
+// NewProdukBundleRepository - replica boleh nil, query list lalu jalan di primary
+func NewProdukBundleRepository(db, replica *sql.DB) ProdukBundleRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &produkBundleRepository{db: db, readDB: replica}
+}
+
+const bundleItemQuery = `
+	SELECT b.bundle_id, b.component_id, p.name, b.quantity
+	FROM produk_bundle_items b
+	JOIN produk p ON p.id = b.component_id`
+
+func (repo *produkBundleRepository) GetByBundle(ctx context.Context, bundleIDs ...int) ([]models.BundleItem, error) {
+	if len(bundleIDs) == 0 {
+		return make([]models.BundleItem, 0), nil
+	}
+
+	placeholders := make([]string, len(bundleIDs))
+	args := make([]interface{}, len(bundleIDs))
+	for i, id := range bundleIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	query := bundleItemQuery + " WHERE b.bundle_id IN (" + strings.Join(placeholders, ", ") + ") ORDER BY b.bundle_id, b.id"
+	return repo.query(ctx, conn(ctx, repo.readDB), query, args...)
+}
+
+func (repo *produkBundleRepository) GetByComponent(ctx context.Context, componentID int) ([]models.BundleItem, error) {
+	query := bundleItemQuery + " WHERE b.component_id = $1 ORDER BY b.bundle_id"
+	return repo.query(ctx, conn(ctx, repo.db), query, componentID)
+}
+
+func (repo *produkBundleRepository) query(ctx context.Context, db dbtx, query string, args ...interface{}) ([]models.BundleItem, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.BundleItem, 0)
+	for rows.Next() {
+		var item models.BundleItem
+		if err := rows.Scan(&item.BundleID, &item.ComponentID, &item.ComponentName, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (repo *produkBundleRepository) SetItems(ctx context.Context, bundleID int, items []models.BundleItem) error {
+	return NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		tx := conn(ctx, repo.db)
+		if _, err := tx.ExecContext(ctx, "DELETE FROM produk_bundle_items WHERE bundle_id = $1", bundleID); err != nil {
+			return err
+		}
+
+		for i := range items {
+			items[i].BundleID = bundleID
+			query := "INSERT INTO produk_bundle_items (bundle_id, component_id, quantity) VALUES ($1, $2, $3)"
+			if _, err := tx.ExecContext(ctx, query, bundleID, items[i].ComponentID, items[i].Quantity); err != nil {
+				return err
+			}
+		}
+
+		// Isi paket berubah = produknya berubah, ETag lama tidak berlaku lagi
+		_, err := tx.ExecContext(ctx, "UPDATE produk SET version = version + 1 WHERE id = $1", bundleID)
+		return err
+	})
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"kasirApi/models"
+)
+
+// GetPriceHistory - riwayat harga produk dan variannya, terbaru di atas
+func (repo *produkRepository) GetPriceHistory(ctx context.Context, produkID int) ([]models.PriceHistory, error) {
+	query := "SELECT id, produk_id, variant_id, price, effective_from FROM produk_price_history WHERE produk_id = $1 ORDER BY effective_from DESC, id DESC"
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, query, produkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.PriceHistory, 0)
+	for rows.Next() {
+		var h models.PriceHistory
+		var variantID sql.NullInt64
+		if err := rows.Scan(&h.ID, &h.ProdukID, &variantID, &h.Price, &h.EffectiveFrom); err != nil {
+			return nil, err
+		}
+		h.VariantID = int(variantID.Int64)
+		history = append(history, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// recordPrice - catat harga baru ke riwayat, dilewati kalau sama dengan harga
+// terakhir. variantID 0 artinya harga produk induk
+func recordPrice(ctx context.Context, db dbtx, produkID, variantID, price int) error {
+	query := "SELECT price FROM produk_price_history WHERE produk_id = $1 AND variant_id IS NULL ORDER BY effective_from DESC, id DESC LIMIT 1"
+	args := []interface{}{produkID}
+	if variantID != 0 {
+		query = "SELECT price FROM produk_price_history WHERE produk_id = $1 AND variant_id = $2 ORDER BY effective_from DESC, id DESC LIMIT 1"
+		args = append(args, variantID)
+	}
+
+	var last int
+	err := db.QueryRowContext(ctx, query, args...).Scan(&last)
+	if err == nil && last == price {
+		return nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, "INSERT INTO produk_price_history (produk_id, variant_id, price) VALUES ($1, $2, $3)", produkID, nullableID(variantID), price)
+	return err
+}
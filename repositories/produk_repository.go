@@ -1,102 +1,379 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"kasirApi/models"
-
+	"strings"
 )
 
+// ErrVersionConflict - produk sudah diubah orang lain sejak versi yang dikirim client
+var ErrVersionConflict = errors.New("produk sudah diubah sejak terakhir dibaca, silakan ambil ulang")
+
+// ErrProdukNotFound - produk tidak ada, atau sudah diarsipkan untuk operasi
+// yang hanya boleh pada produk aktif (update)
+var ErrProdukNotFound = errors.New("produk tidak ditemukan")
+
+// ErrNotArchived - restore untuk produk / category yang tidak sedang diarsipkan
+var ErrNotArchived = errors.New("data tidak sedang diarsipkan")
+
+// ErrDuplicateCode - SKU atau barcode sudah dipakai produk lain
+var ErrDuplicateCode = errors.New("SKU atau barcode sudah dipakai produk lain")
+
 type produkRepository struct {
 	db *sql.DB
+	// readDB - replica untuk query list, sama dengan db kalau tidak ada replica
+	readDB *sql.DB
+	// driver - pencarian memakai pg_trgm di Postgres, ranking di Go untuk lainnya
+	driver string
 }
 
 type ProdukRepository interface {
-	GetAll(name string) ([]models.Produk, error)
-	Create(produk *models.Produk) error
-	GetByID(id int) (*models.Produk, error)
-	Update(produk *models.Produk) error
-	Delete(id int) error
+	GetAll(ctx context.Context, filter models.ProdukFilter) ([]models.Produk, error)
+	Count(ctx context.Context, filter models.ProdukFilter) (int, error)
+	Create(ctx context.Context, produk *models.Produk) error
+	GetByID(ctx context.Context, id int) (*models.Produk, error)
+	// GetByName - cari produk aktif by nama (case-insensitive), nil kalau tidak ada
+	GetByName(ctx context.Context, name string) (*models.Produk, error)
+	// GetByBarcode - cari produk aktif dari hasil scan barcode, fallback ke SKU
+	GetByBarcode(ctx context.Context, code string) (*models.Produk, error)
+	// Update - kalau produk.Version > 0 hanya update jika versi di database sama,
+	// selain itu ErrVersionConflict. produk.Version diisi versi baru setelah update.
+	// Produk yang diarsipkan tidak bisa diubah, ErrProdukNotFound
+	Update(ctx context.Context, produk *models.Produk) error
+	// Delete - arsipkan produk (soft delete), baris produk tetap ada untuk riwayat transaksi
+	Delete(ctx context.Context, id int) error
+	// Restore - kembalikan produk yang diarsipkan, ErrNotArchived kalau tidak diarsipkan
+	Restore(ctx context.Context, id int) error
+	// GetPriceHistory - riwayat harga produk dan variannya, terbaru di atas
+	GetPriceHistory(ctx context.Context, produkID int) ([]models.PriceHistory, error)
+	// GetStockMovements - buku stok produk dan variannya, terbaru di atas
+	GetStockMovements(ctx context.Context, produkID int) ([]models.StockMovement, error)
+	// AdjustStock - ubah stok sebesar movement.Quantity dan catat ke buku stok,
+	// ErrNegativeStock kalau stok jadi minus
+	AdjustStock(ctx context.Context, movement *models.StockMovement) error
+	// SetImage - simpan key gambar produk (kosong = hapus gambar), versi ikut naik.
+	// ErrProdukNotFound kalau produk tidak ada atau sudah diarsipkan
+	SetImage(ctx context.Context, id int, imageKey string) error
+	// Search - cari produk aktif dari nama, SKU dan category, urut relevansi
+	Search(ctx context.Context, query string, limit int) ([]models.ProdukSearchResult, error)
+}
+
+// NewProdukRepository - replica boleh nil, query list lalu jalan di primary.
+// driver dari database.DriverName, menentukan cara pencarian
+func NewProdukRepository(db, replica *sql.DB, driver string) ProdukRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &produkRepository{db: db, readDB: replica, driver: driver}
 }
 
-func NewProdukRepository(db *sql.DB) ProdukRepository {
-	return &produkRepository{db: db}
+// produkColumns - urutan kolom yang dibaca scanProduk
+const produkColumns = "id, name, price, cost_price, stock, reorder_level, version, category_id, sku, deleted_at, image_key, unit"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
-func (repo *produkRepository) GetAll(nameFilter string) ([]models.Produk, error) {
-	query := "SELECT id, name, price, stock FROM produk"
-	
-	args := []interface{}{}
-	if nameFilter != "" {
-		query += " WHERE name ILIKE $1"
-		args = append(args, "%"+nameFilter+"%")
+func scanProduk(row rowScanner, p *models.Produk) error {
+	var categoryID sql.NullInt64
+	var sku sql.NullString
+	var deletedAt sql.NullTime
+	var imageKey sql.NullString
+	err := row.Scan(&p.ID, &p.Name, &p.Price, &p.CostPrice, &p.Stock, &p.ReorderLevel, &p.Version, &categoryID, &sku, &deletedAt, &imageKey, &p.Unit)
+	if err != nil {
+		return err
+	}
+	p.CategoryID = int(categoryID.Int64)
+	p.SKU = sku.String
+	p.ImageKey = imageKey.String
+	if deletedAt.Valid {
+		p.DeletedAt = &deletedAt.Time
+	}
+	return nil
+}
+
+// nullableID - 0 disimpan sebagai NULL (produk tanpa category)
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableString - string kosong disimpan sebagai NULL (supaya unique index tidak bentrok)
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// produkConditions - WHERE untuk filter produk, dipakai GetAll dan Count
+func produkConditions(filter models.ProdukFilter, args []interface{}) ([]string, []interface{}) {
+	var conditions []string
+	if !filter.IncludeArchived {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if filter.Name != "" {
+		// LOWER + LIKE supaya jalan di Postgres dan SQLite (SQLite tidak kenal ILIKE)
+		args = append(args, "%"+filter.Name+"%")
+		conditions = append(conditions, fmt.Sprintf("LOWER(name) LIKE LOWER($%d)", len(args)))
+	}
+	if filter.CategoryID != 0 {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("category_id = $%d", len(args)))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		conditions = append(conditions, fmt.Sprintf("price >= $%d", len(args)))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		conditions = append(conditions, fmt.Sprintf("price <= $%d", len(args)))
+	}
+	if filter.InStock {
+		conditions = append(conditions, "stock > 0")
 	}
-	
-	rows, err := repo.db.Query(query, args...)
+	if filter.LowStock {
+		conditions = append(conditions, "reorder_level > 0 AND stock <= reorder_level")
+	}
+
+	return conditions, args
+}
+
+func (repo *produkRepository) GetAll(ctx context.Context, filter models.ProdukFilter) ([]models.Produk, error) {
+	cursor, err := decodeProdukCursor(filter)
+	if err != nil {
+		return nil, err
+	}
+	sort := normalizeSort(filter.Sort)
+	column, ok := produkSortColumns[sort]
+	if !ok {
+		return nil, fmt.Errorf("sort %s tidak dikenal", filter.Sort)
+	}
+	direction, compare := "ASC", ">"
+	if filter.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	query := "SELECT " + produkColumns + " FROM produk"
+	conditions, args := produkConditions(filter, []interface{}{})
+
+	// Keyset: lanjut setelah (nilai sort, id) baris terakhir halaman sebelumnya
+	if cursor != nil {
+		if sort == "id" {
+			args = append(args, cursor.ID)
+			conditions = append(conditions, fmt.Sprintf("id %s $%d", compare, len(args)))
+		} else {
+			args = append(args, cursor.sortValue(), cursor.ID)
+			v, id := len(args)-1, len(args)
+			conditions = append(conditions, fmt.Sprintf("(%s %s $%d OR (%s = $%d AND id %s $%d))", column, compare, v, column, v, compare, id))
+		}
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY " + column + " " + direction
+	if sort != "id" {
+		query += ", id " + direction
+	}
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if cursor == nil && filter.Offset > 0 {
+			args = append(args, filter.Offset)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	
+
 	defer rows.Close()
 
 	produk := make([]models.Produk, 0)
 	for rows.Next() {
 		var p models.Produk
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
+		err := scanProduk(rows, &p)
 		if err != nil {
 			return nil, err
 		}
 		produk = append(produk, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := repo.loadBarcodes(ctx, repo.readDB, produk); err != nil {
+		return nil, err
+	}
 
 	return produk, nil
 }
 
+// Count - jumlah produk yang cocok dengan filter, tanpa limit/offset/cursor
+func (repo *produkRepository) Count(ctx context.Context, filter models.ProdukFilter) (int, error) {
+	query := "SELECT COUNT(*) FROM produk"
+	conditions, args := produkConditions(filter, []interface{}{})
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
 
-func (repo *produkRepository) Create(produk *models.Produk) error {
-	query := "INSERT INTO produk (name, price, stock) VALUES ($1, $2, $3) RETURNING id"
-	err := repo.db.QueryRow(query, produk.Name, produk.Price, produk.Stock).Scan(&produk.ID)
-	return err
+	var total int
+	err := conn(ctx, repo.readDB).QueryRowContext(ctx, query, args...).Scan(&total)
+	return total, err
+}
+
+func (repo *produkRepository) Create(ctx context.Context, produk *models.Produk) error {
+	// Insert produk, barcode, harga dan stok awalnya harus bareng
+	return NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		if err := repo.checkCodes(ctx, produk); err != nil {
+			return err
+		}
+
+		query := "INSERT INTO produk (name, price, cost_price, stock, reorder_level, unit, category_id, sku) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, version"
+		err := conn(ctx, repo.db).QueryRowContext(ctx, query, produk.Name, produk.Price, produk.CostPrice, produk.Stock, produk.ReorderLevel, produk.Unit, nullableID(produk.CategoryID), nullableString(produk.SKU)).Scan(&produk.ID, &produk.Version)
+		if err != nil {
+			return err
+		}
+
+		if err := recordPrice(ctx, conn(ctx, repo.db), produk.ID, 0, produk.Price); err != nil {
+			return err
+		}
+		if err := recordStockChange(ctx, conn(ctx, repo.db), produk.ID, 0, 0, produk.Stock, "stok awal"); err != nil {
+			return err
+		}
+		return repo.saveBarcodes(ctx, produk)
+	})
+}
+
+func (repo *produkRepository) GetByID(ctx context.Context, id int) (*models.Produk, error) {
+	query := "SELECT " + produkColumns + " FROM produk WHERE id = $1"
+
+	var p models.Produk
+	err := scanProduk(conn(ctx, repo.db).QueryRowContext(ctx, query, id), &p)
+	if err == sql.ErrNoRows {
+		return nil, ErrProdukNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.withBarcodes(ctx, &p)
 }
 
-// GetByID - ambil produk by ID
-func (repo *produkRepository) GetByID(id int) (*models.Produk, error) {
-	query := "SELECT id, name, price, stock FROM produk WHERE id = $1"
+// GetByName - ambil produk aktif by nama, nil tanpa error kalau tidak ada
+func (repo *produkRepository) GetByName(ctx context.Context, name string) (*models.Produk, error) {
+	query := "SELECT " + produkColumns + " FROM produk WHERE LOWER(name) = LOWER($1) AND deleted_at IS NULL ORDER BY id LIMIT 1"
 
 	var p models.Produk
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
+	err := scanProduk(conn(ctx, repo.db).QueryRowContext(ctx, query, name), &p)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("produk tidak ditemukan")
+		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &p, nil
+	return repo.withBarcodes(ctx, &p)
+}
+
+func (repo *produkRepository) Update(ctx context.Context, produk *models.Produk) error {
+	return NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		if err := repo.checkCodes(ctx, produk); err != nil {
+			return err
+		}
+
+		before, err := lockStock(ctx, conn(ctx, repo.db), "produk", produk.ID)
+		if err == sql.ErrNoRows {
+			return ErrProdukNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		query := "UPDATE produk SET name = $1, price = $2, cost_price = $3, stock = $4, reorder_level = $5, unit = $6, category_id = $7, sku = $8, version = version + 1 WHERE id = $9 AND deleted_at IS NULL"
+		args := []interface{}{produk.Name, produk.Price, produk.CostPrice, produk.Stock, produk.ReorderLevel, produk.Unit, nullableID(produk.CategoryID), nullableString(produk.SKU), produk.ID}
+		if produk.Version > 0 {
+			query += " AND version = $10"
+			args = append(args, produk.Version)
+		}
+		query += " RETURNING version, image_key"
+
+		var imageKey sql.NullString
+		err = conn(ctx, repo.db).QueryRowContext(ctx, query, args...).Scan(&produk.Version, &imageKey)
+		if err == sql.ErrNoRows {
+			// Bedakan produk tidak ada / diarsipkan dengan versi yang sudah basi
+			current, err := repo.GetByID(ctx, produk.ID)
+			if err != nil {
+				return err
+			}
+			if current.DeletedAt != nil {
+				return ErrProdukNotFound
+			}
+			return ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+		produk.ImageKey = imageKey.String
+
+		if err := recordPrice(ctx, conn(ctx, repo.db), produk.ID, 0, produk.Price); err != nil {
+			return err
+		}
+		if err := recordStockChange(ctx, conn(ctx, repo.db), produk.ID, 0, before, produk.Stock, "stok diubah lewat update produk"); err != nil {
+			return err
+		}
+		return repo.saveBarcodes(ctx, produk)
+	})
 }
 
-func (repo *produkRepository) Update(produk *models.Produk) error {
-	query := "UPDATE produk SET name = $1, price = $2, stock = $3 WHERE id = $4"
-	result, err := repo.db.Exec(query, produk.Name, produk.Price, produk.Stock, produk.ID)
+func (repo *produkRepository) Delete(ctx context.Context, id int) error {
+	query := "UPDATE produk SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND deleted_at IS NULL"
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	// Produk yang sudah diarsipkan dianggap tidak ada, sama seperti di list
+	if rows == 0 {
+		return ErrProdukNotFound
+	}
+
+	return err
+}
+
+func (repo *produkRepository) SetImage(ctx context.Context, id int, imageKey string) error {
+	query := "UPDATE produk SET image_key = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NULL"
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, nullableString(imageKey), id)
+	if err != nil {
+		return err
+	}
 	rows, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return ErrProdukNotFound
 	}
 
 	return nil
 }
 
-func (repo *produkRepository) Delete(id int) error {
-	query := "DELETE FROM produk WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+func (repo *produkRepository) Restore(ctx context.Context, id int) error {
+	query := "UPDATE produk SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL"
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -106,8 +383,11 @@ func (repo *produkRepository) Delete(id int) error {
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		if _, err := repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrNotArchived
 	}
 
-	return err
+	return nil
 }
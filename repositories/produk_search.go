@@ -0,0 +1,230 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"kasirApi/database"
+	"kasirApi/models"
+)
+
+// minSearchScore - hasil dengan skor di bawah ini dianggap tidak cocok.
+// Sama dengan batas default pg_trgm.similarity_threshold
+const minSearchScore = 0.3
+
+// searchQuery - skor = kecocokan terbaik dari nama (trigram & kata), SKU dan
+// category (bobot setengah), ditambah ts_rank supaya kata yang persis sama
+// naik ke atas. Kondisi WHERE memakai operator yang didukung index trigram/FTS
+const searchQuery = `
+	WITH ranked AS (
+		SELECT p.id,
+			GREATEST(
+				similarity(LOWER(p.name), LOWER($1)),
+				word_similarity(LOWER($1), LOWER(p.name)),
+				CASE WHEN LOWER(p.sku) = LOWER($1) THEN 1.0
+					WHEN LOWER(p.sku) LIKE LOWER($1) || '%' THEN 0.9
+					ELSE 0 END,
+				COALESCE(word_similarity(LOWER($1), LOWER(c.name)), 0) * 0.5
+			) + ts_rank(to_tsvector('simple', p.name), plainto_tsquery('simple', $1)) AS score
+		FROM produk p
+		LEFT JOIN category c ON c.id = p.category_id
+		WHERE p.deleted_at IS NULL
+			AND (
+				to_tsvector('simple', p.name) @@ plainto_tsquery('simple', $1)
+				OR LOWER(p.name) % LOWER($1)
+				OR LOWER($1) <% LOWER(p.name)
+				OR LOWER(p.sku) LIKE LOWER($1) || '%'
+				OR LOWER($1) <% LOWER(c.name)
+			)
+	)
+	SELECT ` + produkColumns + `, score
+	FROM produk
+	JOIN ranked USING (id)
+	ORDER BY score DESC, id
+	LIMIT $2`
+
+// Search - cari produk aktif dari nama, SKU dan nama category, toleran salah
+// ketik. Postgres memakai full-text + pg_trgm, driver lain memakai RankProduk
+func (repo *produkRepository) Search(ctx context.Context, query string, limit int) ([]models.ProdukSearchResult, error) {
+	if repo.driver != database.DriverPostgres {
+		return repo.searchFallback(ctx, query, limit)
+	}
+
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, searchQuery, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]models.ProdukSearchResult, 0)
+	for rows.Next() {
+		var r models.ProdukSearchResult
+		if err := scanProduk(scoreScanner{rows, &r.Score}, &r.Produk); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	produk := make([]models.Produk, len(results))
+	for i := range results {
+		produk[i] = results[i].Produk
+	}
+	if err := repo.loadBarcodes(ctx, repo.readDB, produk); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Produk = produk[i]
+	}
+
+	return results, nil
+}
+
+// scoreScanner - scanProduk dengan satu kolom skor tambahan di akhir
+type scoreScanner struct {
+	rows  rowScanner
+	score *float64
+}
+
+func (s scoreScanner) Scan(dest ...interface{}) error {
+	return s.rows.Scan(append(dest, s.score)...)
+}
+
+func (repo *produkRepository) searchFallback(ctx context.Context, query string, limit int) ([]models.ProdukSearchResult, error) {
+	produk, err := repo.GetAll(ctx, models.ProdukFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, "SELECT id, name FROM category")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := map[int]string{}
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		categories[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return RankProduk(query, produk, categories, limit), nil
+}
+
+// RankProduk - versi Go dari ranking pencarian Postgres, untuk SQLite dan
+// repository memory. categories berisi nama category per id
+func RankProduk(query string, produk []models.Produk, categories map[int]string, limit int) []models.ProdukSearchResult {
+	q := strings.ToLower(strings.TrimSpace(query))
+	results := make([]models.ProdukSearchResult, 0)
+	if q == "" {
+		return results
+	}
+
+	for _, p := range produk {
+		name := strings.ToLower(p.Name)
+		score := max(
+			trigramSimilarity(name, q),
+			wordSimilarity(q, name),
+			wordSimilarity(q, strings.ToLower(categories[p.CategoryID]))*0.5,
+		)
+		if sku := strings.ToLower(p.SKU); sku != "" {
+			if sku == q {
+				score = max(score, 1)
+			} else if strings.HasPrefix(sku, q) {
+				score = max(score, 0.9)
+			}
+		}
+		// Pengganti ts_rank: semua kata di query muncul utuh di nama
+		if containsWords(name, q) {
+			score += 0.1
+		}
+
+		if score >= minSearchScore {
+			results = append(results, models.ProdukSearchResult{Produk: p, Score: score})
+		}
+	}
+
+	slices.SortFunc(results, func(a, b models.ProdukSearchResult) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// trigrams - himpunan trigram seperti pg_trgm: tiap kata diberi dua spasi
+// di depan dan satu di belakang, huruf/angka saja
+func trigrams(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// trigramSimilarity - jumlah trigram yang sama dibagi gabungan keduanya (0..1)
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// wordSimilarity - seberapa mirip query dengan kata-kata di text. Tiap kata
+// query dicocokkan dengan kata paling mirip di text lalu dirata-rata, jadi
+// "indomi gorng" tetap dekat ke "Indomie Goreng Spesial"
+func wordSimilarity(query, text string) float64 {
+	queryWords, textWords := strings.Fields(query), strings.Fields(text)
+	if len(queryWords) == 0 || len(textWords) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, qw := range queryWords {
+		best := 0.0
+		for _, tw := range textWords {
+			best = max(best, trigramSimilarity(qw, tw))
+		}
+		total += best
+	}
+	return total / float64(len(queryWords))
+}
+
+func containsWords(text, query string) bool {
+	words := strings.Fields(text)
+	for _, qw := range strings.Fields(query) {
+		if !slices.Contains(words, qw) {
+			return false
+		}
+	}
+	return true
+}
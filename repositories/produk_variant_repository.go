@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"kasirApi/models"
+)
+
+type produkVariantRepository struct {
+	db *sql.DB
+	// readDB - replica untuk query list, sama dengan db kalau tidak ada replica
+	readDB *sql.DB
+}
+
+type ProdukVariantRepository interface {
+	// GetByProduk - varian aktif milik produk-produk tersebut, urut produk lalu id
+	GetByProduk(ctx context.Context, produkIDs ...int) ([]models.ProdukVariant, error)
+	// GetByID - varian aktif, varian yang sudah dihapus dianggap tidak ada
+	GetByID(ctx context.Context, id int) (*models.ProdukVariant, error)
+	Create(ctx context.Context, variant *models.ProdukVariant) error
+	Update(ctx context.Context, variant *models.ProdukVariant) error
+	// Delete - arsipkan varian (soft delete), barisnya tetap ada untuk riwayat.
+	// Sisa stoknya dinolkan lewat buku stok
+	Delete(ctx context.Context, id int) error
+}
+
+// NewProdukVariantRepository - replica boleh nil, query list lalu jalan di primary
+func NewProdukVariantRepository(db, replica *sql.DB) ProdukVariantRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &produkVariantRepository{db: db, readDB: replica}
+}
+
+func (repo *produkVariantRepository) GetByProduk(ctx context.Context, produkIDs ...int) ([]models.ProdukVariant, error) {
+	variants := make([]models.ProdukVariant, 0)
+	if len(produkIDs) == 0 {
+		return variants, nil
+	}
+
+	placeholders := make([]string, len(produkIDs))
+	args := make([]interface{}, len(produkIDs))
+	for i, id := range produkIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	query := "SELECT id, produk_id, name, price, cost_price, stock FROM produk_variants WHERE produk_id IN (" + strings.Join(placeholders, ", ") + ") AND deleted_at IS NULL ORDER BY produk_id, id"
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v models.ProdukVariant
+		if err := rows.Scan(&v.ID, &v.ProdukID, &v.Name, &v.Price, &v.CostPrice, &v.Stock); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+func (repo *produkVariantRepository) GetByID(ctx context.Context, id int) (*models.ProdukVariant, error) {
+	query := "SELECT id, produk_id, name, price, cost_price, stock FROM produk_variants WHERE id = $1 AND deleted_at IS NULL"
+
+	var v models.ProdukVariant
+	err := conn(ctx, repo.db).QueryRowContext(ctx, query, id).Scan(&v.ID, &v.ProdukID, &v.Name, &v.Price, &v.CostPrice, &v.Stock)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("varian tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (repo *produkVariantRepository) Create(ctx context.Context, variant *models.ProdukVariant) error {
+	// Insert varian, harga dan stok awalnya harus bareng
+	return NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		query := "INSERT INTO produk_variants (produk_id, name, price, cost_price, stock) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+		err := conn(ctx, repo.db).QueryRowContext(ctx, query, variant.ProdukID, variant.Name, variant.Price, variant.CostPrice, variant.Stock).Scan(&variant.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := recordPrice(ctx, conn(ctx, repo.db), variant.ProdukID, variant.ID, variant.Price); err != nil {
+			return err
+		}
+		return recordStockChange(ctx, conn(ctx, repo.db), variant.ProdukID, variant.ID, 0, variant.Stock, "stok awal")
+	})
+}
+
+func (repo *produkVariantRepository) Update(ctx context.Context, variant *models.ProdukVariant) error {
+	return NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		before, err := lockStock(ctx, conn(ctx, repo.db), "produk_variants", variant.ID)
+		if err == sql.ErrNoRows {
+			return errors.New("varian tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+
+		query := "UPDATE produk_variants SET name = $1, price = $2, cost_price = $3, stock = $4 WHERE id = $5 AND deleted_at IS NULL RETURNING produk_id"
+		err = conn(ctx, repo.db).QueryRowContext(ctx, query, variant.Name, variant.Price, variant.CostPrice, variant.Stock, variant.ID).Scan(&variant.ProdukID)
+		if err == sql.ErrNoRows {
+			return errors.New("varian tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := recordPrice(ctx, conn(ctx, repo.db), variant.ProdukID, variant.ID, variant.Price); err != nil {
+			return err
+		}
+		return recordStockChange(ctx, conn(ctx, repo.db), variant.ProdukID, variant.ID, before, variant.Stock, "stok diubah lewat update varian")
+	})
+}
+
+func (repo *produkVariantRepository) Delete(ctx context.Context, id int) error {
+	return NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		before, err := lockStock(ctx, conn(ctx, repo.db), "produk_variants", id)
+		if err == sql.ErrNoRows {
+			return errors.New("varian tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Sisa stok dinolkan supaya jumlah buku stok varian tetap sama dengan stoknya
+		var produkID int
+		query := "UPDATE produk_variants SET stock = 0, deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL RETURNING produk_id"
+		err = conn(ctx, repo.db).QueryRowContext(ctx, query, id).Scan(&produkID)
+		if err == sql.ErrNoRows {
+			return errors.New("varian tidak ditemukan")
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordStockChange(ctx, conn(ctx, repo.db), produkID, id, before, 0, "varian dihapus")
+	})
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"kasirApi/models"
+)
+
+// ErrNegativeStock - penyesuaian stok membuat stok jadi minus
+var ErrNegativeStock = errors.New("stok tidak boleh minus setelah penyesuaian")
+
+// GetStockMovements - buku stok produk dan variannya, terbaru di atas
+func (repo *produkRepository) GetStockMovements(ctx context.Context, produkID int) ([]models.StockMovement, error) {
+	query := "SELECT id, produk_id, variant_id, type, quantity, reason, user_name, reference, created_at FROM stock_movements WHERE produk_id = $1 ORDER BY id DESC"
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, query, produkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]models.StockMovement, 0)
+	for rows.Next() {
+		var m models.StockMovement
+		var variantID sql.NullInt64
+		if err := rows.Scan(&m.ID, &m.ProdukID, &variantID, &m.Type, &m.Quantity, &m.Reason, &m.User, &m.Reference, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.VariantID = int(variantID.Int64)
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// AdjustStock - tambah / kurangi stok produk (atau varian) sebesar
+// movement.Quantity dan catat ke buku stok. ErrNegativeStock kalau stok jadi minus
+func (repo *produkRepository) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+	return NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		db := conn(ctx, repo.db)
+
+		var stock models.Quantity
+		var err error
+		if movement.VariantID != 0 {
+			query := "UPDATE produk_variants SET stock = ROUND(stock + $1, 3) WHERE id = $2 AND produk_id = $3 AND deleted_at IS NULL RETURNING stock"
+			err = db.QueryRowContext(ctx, query, movement.Quantity, movement.VariantID, movement.ProdukID).Scan(&stock)
+			if err == sql.ErrNoRows {
+				return errors.New("varian tidak ditemukan")
+			}
+		} else {
+			query := "UPDATE produk SET stock = ROUND(stock + $1, 3), version = version + 1 WHERE id = $2 RETURNING stock"
+			err = db.QueryRowContext(ctx, query, movement.Quantity, movement.ProdukID).Scan(&stock)
+			if err == sql.ErrNoRows {
+				return ErrProdukNotFound
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if stock < 0 {
+			return ErrNegativeStock
+		}
+
+		return recordMovement(ctx, db, movement)
+	})
+}
+
+// recordMovement - tambah satu baris buku stok, movement.ID dan CreatedAt
+// diisi dari database. Quantity 0 dilewati
+func recordMovement(ctx context.Context, db dbtx, movement *models.StockMovement) error {
+	if movement.Quantity == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO stock_movements (produk_id, variant_id, type, quantity, reason, user_name, reference) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at"
+	return db.QueryRowContext(ctx, query, movement.ProdukID, nullableID(movement.VariantID), movement.Type, movement.Quantity, movement.Reason, movement.User, movement.Reference).Scan(&movement.ID, &movement.CreatedAt)
+}
+
+// lockStock - stok saat ini sambil mengunci barisnya sampai transaksi selesai,
+// supaya selisih stok yang dicatat tidak balapan dengan checkout. Memakai
+// UPDATE tanpa perubahan karena SQLite tidak punya SELECT ... FOR UPDATE
+func lockStock(ctx context.Context, db dbtx, table string, id int) (models.Quantity, error) {
+	var stock models.Quantity
+	err := db.QueryRowContext(ctx, "UPDATE "+table+" SET stock = stock WHERE id = $1 RETURNING stock", id).Scan(&stock)
+	return stock, err
+}
+
+// recordStockChange - catat selisih stok yang ditimpa langsung lewat
+// create / update produk atau varian sebagai adjustment
+func recordStockChange(ctx context.Context, db dbtx, produkID, variantID int, before, after models.Quantity, reason string) error {
+	return recordMovement(ctx, db, &models.StockMovement{
+		ProdukID:  produkID,
+		VariantID: variantID,
+		Type:      models.MovementAdjustment,
+		Quantity:  after - before,
+		Reason:    reason,
+	})
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -10,30 +11,36 @@ import (
 
 type transactionRepository struct {
 	db *sql.DB
+	// readDB - replica untuk report dan list, sama dengan db kalau tidak ada replica
+	readDB *sql.DB
 }
 
 type TransactionRepository interface {
 	// Update(transaction *models.Transaction) error
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
 	// Checkout(items []models.CheckoutItem, useLock bool) (*models.Transaction, error)
-	CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error)
-	GetSalesReport(startDate, endDate string) (*models.SalesReport, error)
+	CreateTransaction(ctx context.Context, items []models.CheckoutItem) (*models.Transaction, error)
+	GetSalesReport(ctx context.Context, startDate, endDate string) (*models.SalesReport, error)
 }
 
-func NewTransactionRepository(db *sql.DB) TransactionRepository {
-	return &transactionRepository{db: db}
+// NewTransactionRepository - replica boleh nil, report lalu jalan di primary
+func NewTransactionRepository(db, replica *sql.DB) TransactionRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &transactionRepository{db: db, readDB: replica}
 }
 
-func (repo *transactionRepository) GetAll(nameFilter string) ([]models.Transaction, error) {
+func (repo *transactionRepository) GetAll(ctx context.Context, nameFilter string) ([]models.Transaction, error) {
 	query := "SELECT id, name, price, stock FROM transaction"
 
 	args := []interface{}{}
 	if nameFilter != "" {
-		query += " WHERE name ILIKE $1"
+		query += " WHERE LOWER(name) LIKE LOWER($1)"
 		args = append(args, "%"+nameFilter+"%")
 	}
 
-	rows, err := repo.db.Query(query, args...)
+	rows, err := conn(ctx, repo.readDB).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -53,21 +60,61 @@ func (repo *transactionRepository) GetAll(nameFilter string) ([]models.Transacti
 	return transaction, nil
 }
 
-func (repo *transactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
-	tx, err := repo.db.Begin()
+func (repo *transactionRepository) CreateTransaction(ctx context.Context, items []models.CheckoutItem) (*models.Transaction, error) {
+	var transaction *models.Transaction
+
+	// Ikut transaksi UnitOfWork dari service kalau ada, kalau tidak buka transaksi sendiri
+	err := NewUnitOfWork(repo.db).Do(ctx, func(ctx context.Context) error {
+		var err error
+		transaction, err = repo.createTransaction(ctx, conn(ctx, repo.db), items)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer tx.Rollback()
 
+	return transaction, nil
+}
+
+func (repo *transactionRepository) createTransaction(ctx context.Context, tx dbtx, items []models.CheckoutItem) (*models.Transaction, error) {
 	totalAmount := 0
 	details := make([]models.TransactionDetail, 0)
 
 	for _, item := range items {
-		var productPrice, stock int
-		var productName string
+		var productPrice, unitCost int
+		var stock models.Quantity
+		var productName, variantName, unit string
+
+		// Hasil scan kasir: cari product_id dari barcode / SKU
+		if item.ProductID == 0 && item.Barcode != "" {
+			id, err := findProdukIDByCode(ctx, tx, item.Barcode)
+			if err != nil {
+				return nil, err
+			}
+			item.ProductID = id
+		}
+
+		// Varian punya harga dan stok sendiri, produk induknya diambil dari varian.
+		// Varian yang sudah dihapus tidak bisa dijual lagi
+		if item.VariantID != 0 {
+			var produkID int
+			err := tx.QueryRowContext(ctx, "SELECT produk_id, name, price, cost_price, stock FROM produk_variants WHERE id = $1 AND deleted_at IS NULL", item.VariantID).Scan(&produkID, &variantName, &productPrice, &unitCost, &stock)
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("variant id %d not found", item.VariantID)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if item.ProductID != 0 && item.ProductID != produkID {
+				return nil, fmt.Errorf("variant id %d bukan varian product id %d", item.VariantID, item.ProductID)
+			}
+			item.ProductID = produkID
+		}
 
-		err := tx.QueryRow("SELECT name, price, stock FROM produk WHERE id = $1", item.ProductID).Scan(&productName, &productPrice, &stock)
+		var produkPrice, produkCost int
+		var produkStock models.Quantity
+		// Produk yang diarsipkan tidak bisa dijual lagi
+		err := tx.QueryRowContext(ctx, "SELECT name, price, cost_price, stock, unit FROM produk WHERE id = $1 AND deleted_at IS NULL", item.ProductID).Scan(&productName, &produkPrice, &produkCost, &produkStock, &unit)
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("product id %d not found", item.ProductID)
 		}
@@ -75,60 +122,129 @@ func (repo *transactionRepository) CreateTransaction(items []models.CheckoutItem
 			return nil, err
 		}
 
-		if stock < item.Quantity {
-			return nil, fmt.Errorf("stok kurang for product %s", productName)
+		if item.VariantID == 0 {
+			var variantCount int
+			err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM produk_variants WHERE produk_id = $1 AND deleted_at IS NULL", item.ProductID).Scan(&variantCount)
+			if err != nil {
+				return nil, err
+			}
+			if variantCount > 0 {
+				return nil, fmt.Errorf("product %s punya varian, variant_id wajib diisi", productName)
+			}
+			productPrice, unitCost, stock = produkPrice, produkCost, produkStock
+		}
+
+		if err := ValidateQuantity(productName, unit, item.Quantity); err != nil {
+			return nil, err
+		}
+
+		// Paket tidak punya stok sendiri, yang dicek dan dikurangi stok komponennya
+		var components []models.TransactionDetail
+		if item.VariantID == 0 {
+			var bundleCost int
+			components, bundleCost, err = repo.takeBundleComponents(ctx, tx, item.ProductID, productName, item.Quantity)
+			if err != nil {
+				return nil, err
+			}
+			if components != nil {
+				unitCost = bundleCost
+			}
 		}
 
-		subtotal := productPrice * item.Quantity
+		subtotal := item.Quantity.MulPrice(productPrice)
 		totalAmount += subtotal
 
-		_, err = tx.Exec("UPDATE produk SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
-		if err != nil {
-			return nil, err
+		cost := 0
+		if components != nil {
+			// HPP paket = HPP stok komponen yang benar-benar keluar
+			for _, c := range components {
+				cost += c.Cost
+			}
+		} else {
+			cost = item.Quantity.MulPrice(unitCost)
+			if stock < item.Quantity {
+				if variantName != "" {
+					return nil, fmt.Errorf("stok kurang for product %s (%s)", productName, variantName)
+				}
+				return nil, fmt.Errorf("stok kurang for product %s", productName)
+			}
+
+			// ROUND supaya stok pecahan di SQLite (REAL) tidak menumpuk selisih float
+			if item.VariantID != 0 {
+				_, err = tx.ExecContext(ctx, "UPDATE produk_variants SET stock = ROUND(stock - $1, 3) WHERE id = $2", item.Quantity, item.VariantID)
+			} else {
+				_, err = tx.ExecContext(ctx, "UPDATE produk SET stock = ROUND(stock - $1, 3), version = version + 1 WHERE id = $2", item.Quantity, item.ProductID)
+			}
+			if err != nil {
+				return nil, err
+			}
 		}
 
+		// ProductID tetap produk induk, jadi report otomatis menjumlahkan semua varian
 		details = append(details, models.TransactionDetail{
 			ProductID:   item.ProductID,
 			ProductName: productName,
+			VariantID:   item.VariantID,
+			VariantName: variantName,
 			Quantity:    item.Quantity,
+			Unit:        unit,
+			UnitPrice:   productPrice,
 			Subtotal:    subtotal,
+			UnitCost:    unitCost,
+			Cost:        cost,
+			Components:  components,
 		})
 	}
 
 	var transactionID int
-	err = tx.QueryRow("INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id", totalAmount).Scan(&transactionID)
+	err := tx.QueryRowContext(ctx, "INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id", totalAmount).Scan(&transactionID)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(details) > 0 {
-		query := "INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES "
+	// Baris komponen paket disimpan tepat setelah baris paketnya
+	var rows []models.TransactionDetail
+	for _, d := range details {
+		rows = append(rows, d)
+		rows = append(rows, d.Components...)
+	}
+
+	if len(rows) > 0 {
+		query := "INSERT INTO transaction_details (transaction_id, product_id, variant_id, variant_name, quantity, unit, unit_price, subtotal, unit_cost, cost, bundle_id) VALUES "
 		var args []interface{}
 
 		// loop
-		for i, d := range details {
+		for i, d := range rows {
 			d.TransactionID = transactionID
-			n := i * 4
+			n := i * 11
 			// Rumus posisi parameter:
-			// Baris 1: $1, $2, $3, $4
-			// Baris 2: $5, $6, $7, $8
+			// Baris 1: $1 ... $11
+			// Baris 2: $12 ... $22
+			// Nomor $n harus urut sesuai args: SQLite membaca $n sebagai
+			// parameter bernama yang dinomori berdasarkan urutan kemunculan
 
 			// placeholder ke string query
-			query += fmt.Sprintf("($%d, $%d, $%d, $%d),", n+1, n+2, n+3, n+4)
+			query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d),", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11)
 
 			// masukan ke slice artgs
 			// args = append(args, d.TransactionID, d.ProductID, d.Quantity, d.Subtotal)
-			args = append(args, transactionID, d.ProductID, d.Quantity, d.Subtotal)
+			args = append(args, transactionID, d.ProductID, nullableID(d.VariantID), nullableString(d.VariantName), d.Quantity, d.Unit, d.UnitPrice, d.Subtotal, d.UnitCost, d.Cost, nullableID(d.BundleID))
 
 		}
 
 		query = query[:len(query)-1]
 
-		_, err = tx.Exec(query, args...)
+		_, err = tx.ExecContext(ctx, query, args...)
 		if err != nil {
 			return nil, err
 		}
 	}
+
+	for _, m := range SaleMovements(transactionID, details) {
+		if err := recordMovement(ctx, tx, &m); err != nil {
+			return nil, err
+		}
+	}
 	// old ways
 	// for i := range details {
 	// 	details[i].TransactionID = transactionID
@@ -139,10 +255,6 @@ func (repo *transactionRepository) CreateTransaction(items []models.CheckoutItem
 	// 	}
 	// }
 
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-
 	// Update TransactionID di object return agar frontend dapat ID yang benar OPTIONAL NEXT
 	// for i := range details {
 	//     details[i].TransactionID = transactionID
@@ -155,7 +267,112 @@ func (repo *transactionRepository) CreateTransaction(items []models.CheckoutItem
 	}, nil
 }
 
-func (r *transactionRepository) GetSalesReport(startDate, endDate string) (*models.SalesReport, error) {
+// SaleMovements - baris buku stok untuk stok yang keluar karena transaksi:
+// produk / varian yang dijual langsung dan komponen paket. Paketnya sendiri
+// tidak punya stok jadi tidak dicatat
+func SaleMovements(transactionID int, details []models.TransactionDetail) []models.StockMovement {
+	reference := fmt.Sprintf("transaction:%d", transactionID)
+
+	var movements []models.StockMovement
+	for _, d := range details {
+		if d.Components == nil {
+			movements = append(movements, models.StockMovement{
+				ProdukID:  d.ProductID,
+				VariantID: d.VariantID,
+				Type:      models.MovementSale,
+				Quantity:  -d.Quantity,
+				Reference: reference,
+			})
+			continue
+		}
+		for _, c := range d.Components {
+			movements = append(movements, models.StockMovement{
+				ProdukID:  c.ProductID,
+				Type:      models.MovementSale,
+				Quantity:  -c.Quantity,
+				Reason:    "komponen paket " + d.ProductName,
+				Reference: reference,
+			})
+		}
+	}
+	return movements
+}
+
+// takeBundleComponents - kalau produk paket, cek dan kurangi stok semua
+// komponennya lalu kembalikan baris komponen untuk struk dan HPP satu paket.
+// nil kalau bukan paket
+func (repo *transactionRepository) takeBundleComponents(ctx context.Context, tx dbtx, bundleID int, bundleName string, quantity models.Quantity) ([]models.TransactionDetail, int, error) {
+	query := `
+		SELECT b.component_id, p.name, p.unit, p.cost_price, p.stock, p.deleted_at IS NOT NULL, b.quantity
+		FROM produk_bundle_items b
+		JOIN produk p ON p.id = b.component_id
+		WHERE b.bundle_id = $1
+		ORDER BY b.id`
+	rows, err := tx.QueryContext(ctx, query, bundleID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	type component struct {
+		detail   models.TransactionDetail
+		stock    models.Quantity
+		archived bool
+		perUnit  models.Quantity
+	}
+	var found []component
+	for rows.Next() {
+		var c component
+		if err := rows.Scan(&c.detail.ProductID, &c.detail.ProductName, &c.detail.Unit, &c.detail.UnitCost, &c.stock, &c.archived, &c.perUnit); err != nil {
+			return nil, 0, err
+		}
+		found = append(found, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	// Tutup dulu sebelum UPDATE, satu koneksi transaksi tidak bisa dipakai dua query sekaligus
+	rows.Close()
+
+	var components []models.TransactionDetail
+	unitCost := 0
+	for _, c := range found {
+		if c.archived {
+			return nil, 0, fmt.Errorf("komponen %s dari paket %s sudah diarsipkan", c.detail.ProductName, bundleName)
+		}
+
+		need := quantity.Mul(c.perUnit)
+		if c.stock < need {
+			return nil, 0, fmt.Errorf("stok kurang for product %s (komponen %s)", bundleName, c.detail.ProductName)
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE produk SET stock = ROUND(stock - $1, 3), version = version + 1 WHERE id = $2", need, c.detail.ProductID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		c.detail.Quantity = need
+		c.detail.Cost = need.MulPrice(c.detail.UnitCost)
+		unitCost += c.perUnit.MulPrice(c.detail.UnitCost)
+		c.detail.BundleID = bundleID
+		components = append(components, c.detail)
+	}
+
+	return components, unitCost, nil
+}
+
+// ValidateQuantity - quantity checkout harus lebih dari 0, dan bilangan bulat
+// untuk satuan yang tidak bisa dipecah (pcs, box, ...)
+func ValidateQuantity(productName, unit string, quantity models.Quantity) error {
+	if quantity <= 0 {
+		return fmt.Errorf("quantity for product %s harus lebih dari 0", productName)
+	}
+	if !models.Units[unit] && !quantity.IsWhole() {
+		return fmt.Errorf("quantity for product %s harus bilangan bulat (%s)", productName, unit)
+	}
+	return nil
+}
+
+func (r *transactionRepository) GetSalesReport(ctx context.Context, startDate, endDate string) (*models.SalesReport, error) {
 	var report models.SalesReport
 
 	// 1. Query Total Revenue & Total Transaksi
@@ -167,13 +384,27 @@ func (r *transactionRepository) GetSalesReport(startDate, endDate string) (*mode
 		FROM transactions 
 		WHERE created_at >= $1 AND created_at <= $2`
 
-	err := r.db.QueryRow(queryStats, startDate, endDate).Scan(&report.TotalRevenue, &report.TotalTransaksi)
+	err := conn(ctx, r.readDB).QueryRowContext(ctx, queryStats, startDate, endDate).Scan(&report.TotalRevenue, &report.TotalTransaksi)
+	if err != nil {
+		return nil, err
+	}
+
+	// HPP dari snapshot cost tiap baris. Baris komponen tidak ikut, HPP-nya
+	// sudah dijumlah di baris paket
+	queryCOGS := `
+		SELECT COALESCE(SUM(td.cost), 0)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE t.created_at >= $1 AND t.created_at <= $2 AND td.bundle_id IS NULL`
+
+	err = conn(ctx, r.readDB).QueryRowContext(ctx, queryCOGS, startDate, endDate).Scan(&report.TotalCOGS)
 	if err != nil {
 		return nil, err
 	}
 
 	// 2. Query Produk Terlaris (Top 1)
-	// Kita join transaction_details ke produk, lalu filter berdasarkan tanggal transaksi
+	// Kita join transaction_details ke produk, lalu filter berdasarkan tanggal transaksi.
+	// Baris komponen paket tidak ikut, yang dihitung paketnya
 	queryTopProduct := `
 		SELECT 
 			p.name, 
@@ -181,28 +412,71 @@ func (r *transactionRepository) GetSalesReport(startDate, endDate string) (*mode
 		FROM transaction_details td
 		JOIN produk p ON td.product_id = p.id
 		JOIN transactions t ON td.transaction_id = t.id
-		WHERE t.created_at >= $1 AND t.created_at <= $2
+		WHERE t.created_at >= $1 AND t.created_at <= $2 AND td.bundle_id IS NULL
 		GROUP BY p.name
 		ORDER BY total_qty DESC
 		LIMIT 1`
 
-	err = r.db.QueryRow(queryTopProduct, startDate, endDate).Scan(&report.ProdukTerlaris.Nama, &report.ProdukTerlaris.QtyTerjual)
+	err = conn(ctx, r.readDB).QueryRowContext(ctx, queryTopProduct, startDate, endDate).Scan(&report.ProdukTerlaris.Nama, &report.ProdukTerlaris.QtyTerjual)
 
 	// Handle kasus jika tidak ada penjualan sama sekali (sql.ErrNoRows)
-	if err != nil {
+	if err == sql.ErrNoRows {
+		// Kalau belum ada penjualan, set default strip/0 biar gak error
+		report.ProdukTerlaris = models.ProductBestSeller{Nama: "-", QtyTerjual: 0}
+	} else if err != nil {
 		// Jika errornya bukan NoRows, return error beneran
-		if err.Error() != "sql: no rows in result set" {
-			// Kalau belum ada penjualan, set default strip/0 biar gak error
-			report.ProdukTerlaris = models.ProductBestSeller{Nama: "-", QtyTerjual: 0}
-		}
+		return nil, err
+	}
+
+	// 3. Rincian per produk, komponen paket dihitung terpisah dari penjualan langsung
+	report.Produk, err = r.productSales(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
 
 	return &report, nil
 }
 
-func (repo *transactionRepository) Delete(id int) error {
-	query := "DELETE FROM transaction WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+func (r *transactionRepository) productSales(ctx context.Context, startDate, endDate string) ([]models.ProductSales, error) {
+	query := `
+		SELECT
+			td.product_id,
+			p.name,
+			COALESCE(SUM(CASE WHEN td.bundle_id IS NULL THEN td.quantity ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN td.bundle_id IS NOT NULL THEN td.quantity ELSE 0 END), 0),
+			COALESCE(SUM(td.subtotal), 0),
+			COALESCE(SUM(CASE WHEN td.bundle_id IS NULL THEN td.cost ELSE 0 END), 0)
+		FROM transaction_details td
+		JOIN produk p ON td.product_id = p.id
+		JOIN transactions t ON td.transaction_id = t.id
+		WHERE t.created_at >= $1 AND t.created_at <= $2
+		GROUP BY td.product_id, p.name
+		ORDER BY td.product_id`
+
+	rows, err := conn(ctx, r.readDB).QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := make([]models.ProductSales, 0)
+	for rows.Next() {
+		var s models.ProductSales
+		if err := rows.Scan(&s.ProductID, &s.Nama, &s.QtyTerjual, &s.QtyViaBundle, &s.Revenue, &s.COGS); err != nil {
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sales, nil
+}
+
+func (repo *transactionRepository) Delete(ctx context.Context, id int) error {
+	query := "DELETE FROM transactions WHERE id = $1"
+	result, err := conn(ctx, repo.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// UnitOfWork - menjalankan beberapa pemanggilan repository dalam satu transaksi.
+// fn menerima ctx yang membawa transaksi, semua repository yang dipanggil dengan
+// ctx tersebut ikut transaksi yang sama. Commit kalau fn return nil, rollback
+// kalau fn return error atau panic. Do di dalam Do ikut transaksi yang luar.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type sqlUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) UnitOfWork {
+	return &sqlUnitOfWork{db: db}
+}
+
+type txKey struct{}
+
+func (u *sqlUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rollback setelah Commit tidak berpengaruh, jadi aman untuk error dan panic
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dbtx - method yang sama-sama dimiliki *sql.DB dan *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn - transaksi dari UnitOfWork kalau ada di ctx, selain itu db biasa.
+// Query baca di dalam transaksi juga lewat tx supaya melihat data yang belum di-commit
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"kasirApi/seed"
+)
+
+// runSeed - subcommand: kasirApi seed fixtures/demo.yaml [file lain...]
+func runSeed(ctx context.Context, seeder *seed.Seeder, files []string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("usage: seed <file.json|file.yaml>...")
+	}
+
+	for _, file := range files {
+		catalog, err := seed.LoadFile(file)
+		if err != nil {
+			return err
+		}
+
+		result, err := seeder.Run(ctx, catalog)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		fmt.Printf("%s: %d category baru, %d produk baru, %d produk di-update\n",
+			file, result.CategoriesCreated, result.ProdukCreated, result.ProdukUpdated)
+	}
+
+	return nil
+}
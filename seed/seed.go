@@ -0,0 +1,191 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+	"kasirApi/services"
+)
+
+// Catalog - isi file fixture, format JSON atau YAML:
+//
+//	categories:
+//	  - name: Makanan
+//	produk:
+//	  - name: Indomie Goreng
+//	    category: Makanan
+//	    price: 3500
+//	    stock: 100
+type Catalog struct {
+	Categories []CategoryFixture `json:"categories" yaml:"categories"`
+	Produk     []ProdukFixture   `json:"produk" yaml:"produk"`
+}
+
+type CategoryFixture struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+type ProdukFixture struct {
+	Name         string          `json:"name" yaml:"name"`
+	Category     string          `json:"category" yaml:"category"`
+	Price        int             `json:"price" yaml:"price"`
+	CostPrice    int             `json:"cost_price" yaml:"cost_price"`
+	Stock        models.Quantity `json:"stock" yaml:"stock"`
+	ReorderLevel models.Quantity `json:"reorder_level" yaml:"reorder_level"`
+	Unit         string          `json:"unit" yaml:"unit"`
+	SKU          string          `json:"sku" yaml:"sku"`
+	Barcodes     []string        `json:"barcodes" yaml:"barcodes"`
+}
+
+// Result - ringkasan hasil seed
+type Result struct {
+	CategoriesCreated int
+	ProdukCreated     int
+	ProdukUpdated     int
+}
+
+// LoadFile - baca fixture, format ditentukan dari ekstensi (.json, .yaml, .yml)
+func LoadFile(path string) (*Catalog, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog Catalog
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &catalog)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &catalog)
+	default:
+		return nil, fmt.Errorf("format fixture tidak dikenal: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &catalog, nil
+}
+
+type Seeder struct {
+	categoryService *services.CategoryService
+	produkService   *services.ProdukService
+	uow             repositories.UnitOfWork
+}
+
+func NewSeeder(categoryService *services.CategoryService, produkService *services.ProdukService, uow repositories.UnitOfWork) *Seeder {
+	return &Seeder{categoryService: categoryService, produkService: produkService, uow: uow}
+}
+
+// Run - upsert category dan produk berdasarkan nama dalam satu transaksi.
+// Category yang sudah ada dibiarkan, produk yang sudah ada di-update harga,
+// stok dan category-nya. Category yang dipakai produk dibuat kalau belum ada.
+func (s *Seeder) Run(ctx context.Context, catalog *Catalog) (Result, error) {
+	var result Result
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		result = Result{}
+		categoryIDs := map[string]int{}
+
+		for _, c := range catalog.Categories {
+			if _, err := s.upsertCategory(ctx, c.Name, categoryIDs, &result); err != nil {
+				return err
+			}
+		}
+
+		for _, p := range catalog.Produk {
+			if err := s.upsertProduk(ctx, p, categoryIDs, &result); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *Seeder) upsertCategory(ctx context.Context, name string, categoryIDs map[string]int, result *Result) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("nama category wajib diisi")
+	}
+
+	key := strings.ToLower(name)
+	if id, ok := categoryIDs[key]; ok {
+		return id, nil
+	}
+
+	existing, err := s.categoryService.GetByName(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		categoryIDs[key] = existing.ID
+		return existing.ID, nil
+	}
+
+	category := models.Category{Name: name}
+	if err := s.categoryService.Create(ctx, &category); err != nil {
+		return 0, fmt.Errorf("category %s: %w", name, err)
+	}
+	result.CategoriesCreated++
+	categoryIDs[key] = category.ID
+
+	return category.ID, nil
+}
+
+func (s *Seeder) upsertProduk(ctx context.Context, fixture ProdukFixture, categoryIDs map[string]int, result *Result) error {
+	name := strings.TrimSpace(fixture.Name)
+	if name == "" {
+		return fmt.Errorf("nama produk wajib diisi")
+	}
+
+	produk := models.Produk{
+		Name:         name,
+		Price:        fixture.Price,
+		CostPrice:    fixture.CostPrice,
+		Stock:        fixture.Stock,
+		ReorderLevel: fixture.ReorderLevel,
+		Unit:         fixture.Unit,
+		SKU:          fixture.SKU,
+		Barcodes:     fixture.Barcodes,
+	}
+
+	if fixture.Category != "" {
+		categoryID, err := s.upsertCategory(ctx, fixture.Category, categoryIDs, result)
+		if err != nil {
+			return err
+		}
+		produk.CategoryID = categoryID
+	}
+
+	existing, err := s.produkService.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if err := s.produkService.Create(ctx, &produk); err != nil {
+			return fmt.Errorf("produk %s: %w", name, err)
+		}
+		result.ProdukCreated++
+		return nil
+	}
+
+	produk.ID = existing.ID
+	if err := s.produkService.Update(ctx, &produk); err != nil {
+		return fmt.Errorf("produk %s: %w", name, err)
+	}
+	result.ProdukUpdated++
+
+	return nil
+}
@@ -1,34 +1,58 @@
 package services
 
 import (
+	"context"
 	"kasirApi/models"
 	"kasirApi/repositories"
 )
 
 type CategoryService struct {
-	repo *repositories.CategoryRepository
+	repo     repositories.CategoryRepository
+	timeouts Timeouts
 }
 
-func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService {
-	return &CategoryService{repo: repo}
+func NewCategoryService(repo repositories.CategoryRepository, timeouts Timeouts) *CategoryService {
+	return &CategoryService{repo: repo, timeouts: timeouts}
 }
 
-func (s *CategoryService) GetAll() ([]models.Category, error) {
-	return s.repo.GetAll()
+func (s *CategoryService) GetAll(ctx context.Context, includeArchived bool) ([]models.Category, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	return s.repo.GetAll(ctx, includeArchived)
 }
 
-func (s *CategoryService) Create(data *models.Category) error {
-	return s.repo.Create(data)
+func (s *CategoryService) Create(ctx context.Context, data *models.Category) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	return s.repo.Create(ctx, data)
 }
 
-func (s *CategoryService) GetByID(id int) (*models.Category, error) {
-	return s.repo.GetByID(id)
+func (s *CategoryService) GetByID(ctx context.Context, id int) (*models.Category, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *CategoryService) Update(category *models.Category) error {
-	return s.repo.Update(category)
+func (s *CategoryService) GetByName(ctx context.Context, name string) (*models.Category, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	return s.repo.GetByName(ctx, name)
 }
 
-func (s *CategoryService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *CategoryService) Update(ctx context.Context, category *models.Category) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	return s.repo.Update(ctx, category)
+}
+
+func (s *CategoryService) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *CategoryService) Restore(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	return s.repo.Restore(ctx, id)
 }
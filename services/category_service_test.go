@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+	"kasirApi/repositories/memory"
+)
+
+func TestUpdateArchivedCategory(t *testing.T) {
+	ctx := context.Background()
+	s := NewCategoryService(memory.NewCategoryRepository(memory.NewStore()), DefaultTimeouts())
+
+	category := &models.Category{Name: "Minuman"}
+	if err := s.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, category.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.Update(ctx, &models.Category{ID: category.ID, Name: "Makanan"})
+	if !errors.Is(err, repositories.ErrCategoryNotFound) {
+		t.Fatalf("update category yang diarsipkan = %v, mau ErrCategoryNotFound", err)
+	}
+	current, err := s.GetByID(ctx, category.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Name != "Minuman" {
+		t.Errorf("nama category yang diarsipkan berubah jadi %q", current.Name)
+	}
+
+	// Setelah di-restore bisa diubah lagi
+	if err := s.Restore(ctx, category.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(ctx, &models.Category{ID: category.ID, Name: "Makanan"}); err != nil {
+		t.Errorf("update category yang di-restore: %v", err)
+	}
+}
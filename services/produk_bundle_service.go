@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kasirApi/models"
+)
+
+// GetBundle - komponen paket, kosong kalau produk bukan paket
+func (s *ProdukService) GetBundle(ctx context.Context, produkID int) ([]models.BundleItem, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if _, err := s.repo.GetByID(ctx, produkID); err != nil {
+		return nil, err
+	}
+	return s.bundleRepo.GetByBundle(ctx, produkID)
+}
+
+// SetBundle - jadikan produk paket dengan komponen items (ganti semua komponen
+// lama). items kosong = produk bukan paket lagi. Paket tidak boleh bersarang
+// dan tidak bisa dipakai bersama varian
+func (s *ProdukService) SetBundle(ctx context.Context, produkID int, items []models.BundleItem) (*models.Produk, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	bundle, err := s.repo.GetByID(ctx, produkID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) > 0 {
+		if err := s.checkBundleCandidate(ctx, bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := map[int]bool{}
+	for _, item := range items {
+		if item.ComponentID == produkID {
+			return nil, errors.New("paket tidak bisa berisi dirinya sendiri")
+		}
+		if seen[item.ComponentID] {
+			return nil, fmt.Errorf("komponen product id %d disebut lebih dari sekali", item.ComponentID)
+		}
+		seen[item.ComponentID] = true
+
+		if err := s.validateBundleComponent(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.bundleRepo.SetItems(ctx, produkID, items); err != nil {
+		return nil, err
+	}
+
+	produk, err := s.repo.GetByID(ctx, produkID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withVariants(ctx, produk)
+}
+
+// checkBundleCandidate - produk yang mau dijadikan paket tidak punya varian
+// dan bukan komponen paket lain
+func (s *ProdukService) checkBundleCandidate(ctx context.Context, produk *models.Produk) error {
+	variants, err := s.variantRepo.GetByProduk(ctx, produk.ID)
+	if err != nil {
+		return err
+	}
+	if len(variants) > 0 {
+		return fmt.Errorf("product %s punya varian, tidak bisa jadi paket", produk.Name)
+	}
+
+	parents, err := s.bundleRepo.GetByComponent(ctx, produk.ID)
+	if err != nil {
+		return err
+	}
+	if len(parents) > 0 {
+		return fmt.Errorf("product %s sudah jadi komponen paket lain", produk.Name)
+	}
+	return nil
+}
+
+func (s *ProdukService) validateBundleComponent(ctx context.Context, item models.BundleItem) error {
+	component, err := s.repo.GetByID(ctx, item.ComponentID)
+	if err != nil {
+		return fmt.Errorf("komponen product id %d: %v", item.ComponentID, err)
+	}
+	if component.DeletedAt != nil {
+		return fmt.Errorf("komponen %s sudah diarsipkan", component.Name)
+	}
+	if item.Quantity <= 0 {
+		return fmt.Errorf("quantity komponen %s harus lebih dari 0", component.Name)
+	}
+	if !models.Units[component.Unit] && !item.Quantity.IsWhole() {
+		return fmt.Errorf("quantity komponen %s harus bilangan bulat (%s)", component.Name, component.Unit)
+	}
+
+	// Stok yang dikurangi checkout adalah stok produk, jadi komponen tidak boleh punya varian
+	variants, err := s.variantRepo.GetByProduk(ctx, component.ID)
+	if err != nil {
+		return err
+	}
+	if len(variants) > 0 {
+		return fmt.Errorf("komponen %s punya varian, tidak bisa masuk paket", component.Name)
+	}
+
+	nested, err := s.bundleRepo.GetByBundle(ctx, component.ID)
+	if err != nil {
+		return err
+	}
+	if len(nested) > 0 {
+		return fmt.Errorf("komponen %s adalah paket, paket tidak bisa bersarang", component.Name)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+// produkCSVColumns - kolom export, import juga menerima urutan lain
+var produkCSVColumns = []string{"name", "category", "price", "cost_price", "stock", "reorder_level", "unit", "sku"}
+
+// errImportRollback - dipakai untuk membatalkan transaksi import saat
+// dry-run atau ada baris yang gagal
+var errImportRollback = errors.New("import dibatalkan")
+
+type ProdukCSVService struct {
+	produkService   *ProdukService
+	categoryService *CategoryService
+	uow             repositories.UnitOfWork
+}
+
+func NewProdukCSVService(produkService *ProdukService, categoryService *CategoryService, uow repositories.UnitOfWork) *ProdukCSVService {
+	return &ProdukCSVService{produkService: produkService, categoryService: categoryService, uow: uow}
+}
+
+// produkCSVRow - satu baris CSV yang sudah di-parse
+type produkCSVRow struct {
+	line     int
+	name     string
+	category string
+	price    int
+	cost     int
+	stock    models.Quantity
+	reorder  models.Quantity
+	unit     string
+	sku      string
+	// filled - kolom opsional yang ada di header dan terisi di baris ini.
+	// Produk yang sudah ada hanya di-update di kolom-kolom ini
+	filled map[string]bool
+}
+
+// Import - upsert produk dari CSV dengan header name, category, price,
+// cost_price, stock, reorder_level, unit, sku.
+// Produk dicocokkan lewat SKU kalau diisi, selain itu lewat nama. Untuk
+// produk yang sudah ada, kolom opsional yang tidak ada atau kosong tidak
+// mengubah nilai lama. Category
+// dibuat kalau belum ada. Semua baris jalan dalam satu transaksi: kalau ada
+// satu baris gagal atau dryRun, semuanya di-rollback dan hasilnya tetap
+// dilaporkan per baris.
+func (s *ProdukCSVService) Import(ctx context.Context, r io.Reader, dryRun bool) (*models.ImportResult, error) {
+	rows, rowErrors, err := parseProdukCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *models.ImportResult
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		result = &models.ImportResult{DryRun: dryRun, Rows: len(rows) + len(rowErrors), Errors: rowErrors}
+		categoryIDs := map[string]int{}
+
+		for _, row := range rows {
+			if err := s.upsert(ctx, row, categoryIDs, result); err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+				result.Errors = append(result.Errors, models.ImportRowError{Row: row.line, Message: err.Error()})
+				// Setelah error database, transaksi Postgres tidak bisa dipakai lagi
+				break
+			}
+		}
+
+		if dryRun || len(result.Errors) > 0 {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+
+	if result.Errors == nil {
+		result.Errors = []models.ImportRowError{}
+	}
+	return result, nil
+}
+
+func (s *ProdukCSVService) upsert(ctx context.Context, row produkCSVRow, categoryIDs map[string]int, result *models.ImportResult) error {
+	produk := models.Produk{
+		Name:         row.name,
+		Price:        row.price,
+		CostPrice:    row.cost,
+		Stock:        row.stock,
+		ReorderLevel: row.reorder,
+		Unit:         row.unit,
+		SKU:          row.sku,
+	}
+
+	if row.category != "" {
+		key := strings.ToLower(row.category)
+		id, ok := categoryIDs[key]
+		if !ok {
+			category, err := s.categoryService.GetByName(ctx, row.category)
+			if err != nil {
+				return err
+			}
+			if category == nil {
+				category = &models.Category{Name: row.category}
+				if err := s.categoryService.Create(ctx, category); err != nil {
+					return fmt.Errorf("category %s: %w", row.category, err)
+				}
+				result.CategoriesCreated++
+			}
+			id = category.ID
+			categoryIDs[key] = id
+		}
+		produk.CategoryID = id
+	}
+
+	existing, err := s.findExisting(ctx, row)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if err := s.produkService.Create(ctx, &produk); err != nil {
+			return err
+		}
+		result.ProdukCreated++
+		return nil
+	}
+
+	// Kolom yang tidak ada di file atau dikosongkan tetap seperti sebelumnya,
+	// barcode juga tidak ada di CSV (nil = tidak diubah)
+	updated := *existing
+	updated.Version = 0
+	updated.Barcodes = nil
+	updated.Name = produk.Name
+	updated.Price = produk.Price
+	if row.filled["category"] {
+		updated.CategoryID = produk.CategoryID
+	}
+	if row.filled["cost_price"] {
+		updated.CostPrice = produk.CostPrice
+	}
+	if row.filled["stock"] {
+		updated.Stock = produk.Stock
+	}
+	if row.filled["reorder_level"] {
+		updated.ReorderLevel = produk.ReorderLevel
+	}
+	if row.filled["unit"] {
+		updated.Unit = produk.Unit
+	}
+	if row.filled["sku"] {
+		updated.SKU = produk.SKU
+	}
+	if err := s.produkService.Update(ctx, &updated); err != nil {
+		return err
+	}
+	result.ProdukUpdated++
+
+	return nil
+}
+
+// findExisting - produk yang akan di-update: cocok SKU dulu, lalu nama
+func (s *ProdukCSVService) findExisting(ctx context.Context, row produkCSVRow) (*models.Produk, error) {
+	if row.sku != "" {
+		// GetByBarcode juga mencocokkan barcode, pastikan yang ketemu memang SKU-nya
+		produk, err := s.produkService.GetByBarcode(ctx, row.sku)
+		if err == nil && produk.SKU == row.sku {
+			return produk, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return s.produkService.GetByName(ctx, row.name)
+}
+
+// parseProdukCSV - baca dan validasi semua baris. Error format per baris
+// dikumpulkan, error di header membatalkan seluruh import
+func parseProdukCSV(r io.Reader) ([]produkCSVRow, []models.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("file CSV kosong")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("header CSV tidak valid: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		// Excel suka menambahkan BOM di awal file
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("kolom %s wajib ada di header CSV", required)
+		}
+	}
+
+	var rows []produkCSVRow
+	var rowErrors []models.ImportRowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, models.ImportRowError{Row: parseErr.StartLine, Message: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := produkCSVRow{
+			line:     line,
+			name:     field("name"),
+			category: field("category"),
+			unit:     field("unit"),
+			sku:      field("sku"),
+			filled:   map[string]bool{},
+		}
+		for _, name := range produkCSVColumns {
+			if field(name) != "" {
+				row.filled[name] = true
+			}
+		}
+		var messages []string
+		if row.name == "" {
+			messages = append(messages, "name wajib diisi")
+		}
+		if row.price, err = strconv.Atoi(field("price")); err != nil || row.price < 0 {
+			messages = append(messages, "price harus angka bulat >= 0")
+		}
+		if v := field("cost_price"); v != "" {
+			if row.cost, err = strconv.Atoi(v); err != nil || row.cost < 0 {
+				messages = append(messages, "cost_price harus angka bulat >= 0")
+			}
+		}
+		if v := field("stock"); v != "" {
+			if row.stock, err = models.ParseQuantity(v); err != nil || row.stock < 0 {
+				messages = append(messages, "stock harus angka >= 0, maksimal 3 angka di belakang koma")
+			}
+		}
+		if v := field("reorder_level"); v != "" {
+			if row.reorder, err = models.ParseQuantity(v); err != nil || row.reorder < 0 {
+				messages = append(messages, "reorder_level harus angka >= 0, maksimal 3 angka di belakang koma")
+			}
+		}
+
+		if len(messages) > 0 {
+			rowErrors = append(rowErrors, models.ImportRowError{Row: line, Message: strings.Join(messages, ", ")})
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// Export - tulis semua produk aktif sebagai CSV dengan kolom yang sama
+// dengan Import, jadi hasilnya bisa diedit lalu di-import ulang
+func (s *ProdukCSVService) Export(ctx context.Context, w io.Writer, includeArchived bool) error {
+	produk, err := s.produkService.GetAll(ctx, models.ProdukFilter{ExpandCategory: true, IncludeArchived: includeArchived})
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(produkCSVColumns); err != nil {
+		return err
+	}
+	for _, p := range produk {
+		category := ""
+		if p.Category != nil {
+			category = p.Category.Name
+		}
+		record := []string{p.Name, category, strconv.Itoa(p.Price), strconv.Itoa(p.CostPrice), p.Stock.String(), p.ReorderLevel.String(), p.Unit, p.SKU}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"kasirApi/imaging"
+	"kasirApi/models"
+	"kasirApi/repositories"
+)
+
+const (
+	// MaxImageSize - batas ukuran file gambar produk
+	MaxImageSize = 5 << 20
+	// maxImagePixels - tolak gambar raksasa sebelum di-decode ke memori
+	maxImagePixels = 40_000_000
+	thumbnailSize  = 256
+)
+
+var (
+	ErrImageTooLarge    = errors.New("ukuran gambar maksimal 5 MB dan 40 megapixel")
+	ErrUnsupportedImage = errors.New("gambar harus JPEG, PNG atau GIF")
+)
+
+// imageExtensions - content type yang diterima dan ekstensi file-nya
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// UploadImage - validasi, simpan gambar dan thumbnail-nya, lalu pasang ke produk.
+// Gambar lama dihapus dari storage setelah gambar baru tersimpan
+func (s *ProdukService) UploadImage(ctx context.Context, id int, data []byte) (*models.Produk, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	if len(data) > MaxImageSize {
+		return nil, ErrImageTooLarge
+	}
+	// Percaya isi file, bukan Content-Type yang dikirim client
+	ext, ok := imageExtensions[http.DetectContentType(data)]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	produk, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Produk yang diarsipkan tidak bisa diubah, termasuk gambarnya
+	if produk.DeletedAt != nil {
+		return nil, repositories.ErrProdukNotFound
+	}
+
+	// Nama file dari hash isi, jadi URL berubah kalau gambar berubah dan boleh di-cache lama
+	sum := sha256.Sum256(data)
+	key := fmt.Sprintf("produk/%d/%x%s", id, sum[:8], ext)
+	thumbKey := thumbnailKey(key)
+
+	var thumb bytes.Buffer
+	if path.Ext(thumbKey) == ".jpg" {
+		err = jpeg.Encode(&thumb, imaging.Thumbnail(img, thumbnailSize), &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&thumb, imaging.Thumbnail(img, thumbnailSize))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.images.Put(ctx, key, bytes.NewReader(data), "image/"+strings.TrimPrefix(ext, ".")); err != nil {
+		return nil, err
+	}
+	if err := s.images.Put(ctx, thumbKey, &thumb, "image/"+strings.TrimPrefix(path.Ext(thumbKey), ".")); err != nil {
+		s.removeImage(ctx, key)
+		return nil, err
+	}
+	if err := s.repo.SetImage(ctx, id, key); err != nil {
+		s.removeImage(ctx, key)
+		return nil, err
+	}
+
+	if produk.ImageKey != "" && produk.ImageKey != key {
+		s.removeImage(ctx, produk.ImageKey)
+	}
+
+	produk, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withVariants(ctx, produk)
+}
+
+// DeleteImage - lepas gambar dari produk dan hapus file-nya
+func (s *ProdukService) DeleteImage(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	produk, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if produk.DeletedAt != nil {
+		return repositories.ErrProdukNotFound
+	}
+	if produk.ImageKey == "" {
+		return errors.New("produk belum punya gambar")
+	}
+
+	if err := s.repo.SetImage(ctx, id, ""); err != nil {
+		return err
+	}
+	s.removeImage(ctx, produk.ImageKey)
+
+	return nil
+}
+
+// removeImage - hapus gambar dan thumbnail-nya. Gagal hapus cukup dicatat,
+// data produk sudah tidak menunjuk ke file tersebut
+func (s *ProdukService) removeImage(ctx context.Context, key string) {
+	for _, k := range []string{key, thumbnailKey(key)} {
+		if err := s.images.Delete(ctx, k); err != nil {
+			log.Printf("Gagal menghapus gambar %s: %v", k, err)
+		}
+	}
+}
+
+func (s *ProdukService) setImageURLs(produk []models.Produk) {
+	for i := range produk {
+		s.setImageURL(&produk[i])
+	}
+}
+
+// setImageURL - isi ImageURL dan ThumbnailURL dari ImageKey
+func (s *ProdukService) setImageURL(produk *models.Produk) {
+	produk.ImageURL, produk.ThumbnailURL = "", ""
+	if produk.ImageKey != "" {
+		produk.ImageURL = s.images.URL(produk.ImageKey)
+		produk.ThumbnailURL = s.images.URL(thumbnailKey(produk.ImageKey))
+	}
+}
+
+// thumbnailKey - produk/1/ab12.jpg -> produk/1/ab12_thumb.jpg.
+// Thumbnail selain JPEG disimpan sebagai PNG (GIF animasi cukup frame pertama)
+func thumbnailKey(key string) string {
+	ext := path.Ext(key)
+	base := strings.TrimSuffix(key, ext)
+	if ext != ".jpg" {
+		ext = ".png"
+	}
+	return base + "_thumb" + ext
+}
@@ -1,34 +1,375 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"kasirApi/models"
 	"kasirApi/repositories"
+	"kasirApi/storage"
+	"strings"
 )
 
 type ProdukService struct {
-	repo repositories.ProdukRepository
+	repo         repositories.ProdukRepository
+	categoryRepo repositories.CategoryRepository
+	variantRepo  repositories.ProdukVariantRepository
+	bundleRepo   repositories.ProdukBundleRepository
+	// images - tempat gambar produk, juga dipakai untuk membentuk image_url
+	images   storage.Storage
+	timeouts Timeouts
 }
 
-func NewProdukService(repo repositories.ProdukRepository) *ProdukService {
-	return &ProdukService{repo: repo}
+func NewProdukService(repo repositories.ProdukRepository, categoryRepo repositories.CategoryRepository, variantRepo repositories.ProdukVariantRepository, bundleRepo repositories.ProdukBundleRepository, images storage.Storage, timeouts Timeouts) *ProdukService {
+	return &ProdukService{repo: repo, categoryRepo: categoryRepo, variantRepo: variantRepo, bundleRepo: bundleRepo, images: images, timeouts: timeouts}
 }
 
-func (s *ProdukService) GetAll(name string) ([]models.Produk, error) {
-	return s.repo.GetAll(name)
+func (s *ProdukService) GetAll(ctx context.Context, filter models.ProdukFilter) ([]models.Produk, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	produk, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachVariants(ctx, produk); err != nil {
+		return nil, err
+	}
+	s.setImageURLs(produk)
+	if filter.ExpandCategory {
+		if err := s.expandCategories(ctx, produk); err != nil {
+			return nil, err
+		}
+	}
+
+	return produk, nil
+}
+
+// List - satu halaman produk beserta total dan cursor halaman berikutnya
+func (s *ProdukService) List(ctx context.Context, filter models.ProdukFilter) (*models.ProdukPage, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	// Ambil satu baris lebih untuk tahu masih ada halaman berikutnya atau tidak
+	query := filter
+	if filter.Limit > 0 {
+		query.Limit = filter.Limit + 1
+	}
+	produk, err := s.repo.GetAll(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ProdukPage{Limit: filter.Limit, Offset: filter.Offset}
+	if filter.Cursor != "" {
+		page.Offset = 0
+	}
+	if filter.Limit > 0 && len(produk) > filter.Limit {
+		produk = produk[:filter.Limit]
+		page.NextCursor = repositories.EncodeProdukCursor(filter, produk[len(produk)-1])
+	}
+
+	page.Total, err = s.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachVariants(ctx, produk); err != nil {
+		return nil, err
+	}
+	s.setImageURLs(produk)
+	if filter.ExpandCategory {
+		if err := s.expandCategories(ctx, produk); err != nil {
+			return nil, err
+		}
+	}
+	page.Data = produk
+
+	return page, nil
+}
+
+func (s *ProdukService) Create(ctx context.Context, data *models.Produk) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	if err := validateProduk(data, models.DefaultUnit); err != nil {
+		return err
+	}
+	if err := s.validateCategory(ctx, data.CategoryID); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, data); err != nil {
+		return err
+	}
+	s.setImageURL(data)
+	return nil
+}
+
+func (s *ProdukService) GetByID(ctx context.Context, id int) (*models.Produk, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	produk, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withVariants(ctx, produk)
+}
+
+func (s *ProdukService) GetByName(ctx context.Context, name string) (*models.Produk, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	return s.repo.GetByName(ctx, name)
+}
+
+// GetByBarcode - lookup untuk scanner kasir
+func (s *ProdukService) GetByBarcode(ctx context.Context, code string) (*models.Produk, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	produk, err := s.repo.GetByBarcode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return s.withVariants(ctx, produk)
 }
 
-func (s *ProdukService) Create(data *models.Produk) error {
-	return s.repo.Create(data)
+func (s *ProdukService) Update(ctx context.Context, produk *models.Produk) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	// Category arsip hanya ditolak kalau produk dipindah ke sana
+	current, err := s.repo.GetByID(ctx, produk.ID)
+	if err != nil {
+		return err
+	}
+	if current.DeletedAt != nil {
+		return repositories.ErrProdukNotFound
+	}
+	if err := validateProduk(produk, current.Unit); err != nil {
+		return err
+	}
+	if produk.CategoryID != current.CategoryID {
+		if err := s.validateCategory(ctx, produk.CategoryID); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Update(ctx, produk); err != nil {
+		return err
+	}
+	s.setImageURL(produk)
+	return nil
 }
 
-func (s *ProdukService) GetByID(id int) (*models.Produk, error) {
-	return s.repo.GetByID(id)
+// maxPatchRetries - PATCH dibaca ulang kalau produk berubah di tengah jalan
+// (misal stok berkurang karena checkout), supaya perubahan lain tidak tertimpa
+const maxPatchRetries = 3
+
+// Patch - ubah sebagian field produk. apply menerima salinan produk terbaru
+// dan mengubah field yang dikirim client. version > 0 berarti client memakai
+// If-Match, konflik langsung dikembalikan tanpa retry
+func (s *ProdukService) Patch(ctx context.Context, id, version int, apply func(produk *models.Produk) error) (*models.Produk, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.DeletedAt != nil {
+			return nil, repositories.ErrProdukNotFound
+		}
+		if version > 0 && current.Version != version {
+			return nil, repositories.ErrVersionConflict
+		}
+
+		produk := *current
+		if err := apply(&produk); err != nil {
+			return nil, err
+		}
+		produk.ID = id
+		produk.Version = current.Version
+
+		if err := validateProduk(&produk, current.Unit); err != nil {
+			return nil, err
+		}
+		if produk.CategoryID != current.CategoryID {
+			if err := s.validateCategory(ctx, produk.CategoryID); err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.repo.Update(ctx, &produk)
+		if errors.Is(err, repositories.ErrVersionConflict) && version == 0 && attempt < maxPatchRetries {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return s.withVariants(ctx, &produk)
+	}
 }
 
-func (s *ProdukService) Update(produk *models.Produk) error {
-	return s.repo.Update(produk)
+func (s *ProdukService) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	return s.repo.Delete(ctx, id)
+}
+
+// Search - pencarian produk untuk kasir, hasil urut relevansi
+func (s *ProdukService) Search(ctx context.Context, query string, limit int) ([]models.ProdukSearchResult, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	results, err := s.repo.Search(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	produk := make([]models.Produk, len(results))
+	for i := range results {
+		produk[i] = results[i].Produk
+	}
+	if err := s.attachVariants(ctx, produk); err != nil {
+		return nil, err
+	}
+	s.setImageURLs(produk)
+	for i := range results {
+		results[i].Produk = produk[i]
+	}
+
+	return results, nil
+}
+
+// GetPriceHistory - riwayat harga produk dan variannya, terbaru di atas
+func (s *ProdukService) GetPriceHistory(ctx context.Context, id int) ([]models.PriceHistory, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPriceHistory(ctx, id)
+}
+
+func (s *ProdukService) Restore(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	return s.repo.Restore(ctx, id)
+}
+
+// validateCategory - category_id 0 artinya tanpa category, selain itu harus ada
+// dan tidak sedang diarsipkan
+func (s *ProdukService) validateCategory(ctx context.Context, categoryID int) error {
+	if categoryID == 0 {
+		return nil
+	}
+	if categoryID < 0 {
+		return errors.New("category_id tidak valid")
+	}
+	category, err := s.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	if category.DeletedAt != nil {
+		return errors.New("category sudah diarsipkan")
+	}
+	return nil
+}
+
+// validateProduk - harga pokok tidak negatif, satuan harus dikenal (kosong =
+// fallbackUnit). Stok pecahan hanya boleh untuk satuan yang bisa dipecah (kg, l, m, ...)
+func validateProduk(produk *models.Produk, fallback string) error {
+	if produk.CostPrice < 0 {
+		return errors.New("cost_price tidak boleh negatif")
+	}
+
+	produk.Unit = strings.ToLower(strings.TrimSpace(produk.Unit))
+	if produk.Unit == "" {
+		produk.Unit = fallback
+	}
+
+	fractional, ok := models.Units[produk.Unit]
+	if !ok {
+		return fmt.Errorf("unit %s tidak dikenal", produk.Unit)
+	}
+	if !fractional && !produk.Stock.IsWhole() {
+		return fmt.Errorf("stock produk dengan unit %s harus bilangan bulat", produk.Unit)
+	}
+	if produk.ReorderLevel < 0 {
+		return errors.New("reorder_level tidak boleh negatif")
+	}
+	if !fractional && !produk.ReorderLevel.IsWhole() {
+		return fmt.Errorf("reorder_level produk dengan unit %s harus bilangan bulat", produk.Unit)
+	}
+	return nil
+}
+
+// expandCategories - isi field Category dari category_id masing-masing produk
+func (s *ProdukService) expandCategories(ctx context.Context, produk []models.Produk) error {
+	// Termasuk yang diarsipkan, produk lama masih boleh menunjuk ke sana
+	categories, err := s.categoryRepo.GetAll(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	for i := range produk {
+		if c, ok := byID[produk[i].CategoryID]; ok {
+			produk[i].Category = &c
+		}
+	}
+
+	return nil
+}
+
+// attachVariants - isi field Variants semua produk dengan satu query
+func (s *ProdukService) attachVariants(ctx context.Context, produk []models.Produk) error {
+	if len(produk) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(produk))
+	index := make(map[int]int, len(produk))
+	for i, p := range produk {
+		ids[i] = p.ID
+		index[p.ID] = i
+	}
+
+	variants, err := s.variantRepo.GetByProduk(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	for _, v := range variants {
+		i := index[v.ProdukID]
+		produk[i].Variants = append(produk[i].Variants, v)
+	}
+
+	// Komponen paket ikut diisi di sini, sama-sama relasi satu-ke-banyak produk
+	items, err := s.bundleRepo.GetByBundle(ctx, ids...)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		i := index[item.BundleID]
+		produk[i].BundleItems = append(produk[i].BundleItems, item)
+	}
+
+	return nil
 }
 
-func (s *ProdukService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *ProdukService) withVariants(ctx context.Context, produk *models.Produk) (*models.Produk, error) {
+	list := []models.Produk{*produk}
+	if err := s.attachVariants(ctx, list); err != nil {
+		return nil, err
+	}
+	s.setImageURLs(list)
+	return &list[0], nil
 }
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"kasirApi/models"
+)
+
+// GetStockMovements - buku stok produk dan variannya, terbaru di atas
+func (s *ProdukService) GetStockMovements(ctx context.Context, id int) ([]models.StockMovement, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStockMovements(ctx, id)
+}
+
+// AdjustStock - penyesuaian stok manual (stock opname, barang datang,
+// transfer, retur), stok berubah sebesar movement.Quantity dan tercatat di
+// buku stok. Sale tidak bisa lewat sini, hanya dari checkout
+func (s *ProdukService) AdjustStock(ctx context.Context, movement *models.StockMovement) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	movement.Type = strings.ToLower(strings.TrimSpace(movement.Type))
+	if movement.Type == "" {
+		movement.Type = models.MovementAdjustment
+	}
+	if !slices.Contains(models.ManualMovementTypes, movement.Type) {
+		return fmt.Errorf("type %s tidak bisa dipakai, pilih: %s", movement.Type, strings.Join(models.ManualMovementTypes, ", "))
+	}
+	if movement.Quantity == 0 {
+		return errors.New("quantity tidak boleh 0")
+	}
+	// Barang datang dan retur pembeli selalu menambah stok
+	if (movement.Type == models.MovementReceiving || movement.Type == models.MovementRefund) && movement.Quantity < 0 {
+		return fmt.Errorf("quantity %s harus positif", movement.Type)
+	}
+	movement.Reason = strings.TrimSpace(movement.Reason)
+	movement.User = strings.TrimSpace(movement.User)
+	movement.Reference = strings.TrimSpace(movement.Reference)
+	if movement.Reason == "" {
+		return errors.New("reason wajib diisi")
+	}
+	if movement.User == "" {
+		return errors.New("user wajib diisi")
+	}
+
+	produk, err := s.repo.GetByID(ctx, movement.ProdukID)
+	if err != nil {
+		return err
+	}
+	if produk.DeletedAt != nil {
+		return fmt.Errorf("product %s sudah diarsipkan", produk.Name)
+	}
+	if !models.Units[produk.Unit] && !movement.Quantity.IsWhole() {
+		return fmt.Errorf("quantity produk dengan unit %s harus bilangan bulat", produk.Unit)
+	}
+
+	// Paket tidak punya stok sendiri, produk bervarian stoknya ada di varian
+	items, err := s.bundleRepo.GetByBundle(ctx, produk.ID)
+	if err != nil {
+		return err
+	}
+	if len(items) > 0 {
+		return fmt.Errorf("product %s adalah paket, sesuaikan stok komponennya", produk.Name)
+	}
+	variants, err := s.variantRepo.GetByProduk(ctx, produk.ID)
+	if err != nil {
+		return err
+	}
+	if movement.VariantID == 0 && len(variants) > 0 {
+		return fmt.Errorf("product %s punya varian, variant_id wajib diisi", produk.Name)
+	}
+	if movement.VariantID != 0 && !slices.ContainsFunc(variants, func(v models.ProdukVariant) bool { return v.ID == movement.VariantID }) {
+		return fmt.Errorf("variant id %d bukan varian product %s", movement.VariantID, produk.Name)
+	}
+
+	return s.repo.AdjustStock(ctx, movement)
+}
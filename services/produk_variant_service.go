@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kasirApi/models"
+)
+
+// GetVariants - semua varian satu produk
+func (s *ProdukService) GetVariants(ctx context.Context, produkID int) ([]models.ProdukVariant, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+
+	if _, err := s.repo.GetByID(ctx, produkID); err != nil {
+		return nil, err
+	}
+	return s.variantRepo.GetByProduk(ctx, produkID)
+}
+
+// GetVariant - varian by ID, harus milik produk produkID
+func (s *ProdukService) GetVariant(ctx context.Context, produkID, variantID int) (*models.ProdukVariant, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Read)
+	defer cancel()
+	return s.findVariant(ctx, produkID, variantID)
+}
+
+func (s *ProdukService) CreateVariant(ctx context.Context, variant *models.ProdukVariant) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	produk, err := s.repo.GetByID(ctx, variant.ProdukID)
+	if err != nil {
+		return err
+	}
+	if err := validateVariant(variant, produk.Unit); err != nil {
+		return err
+	}
+
+	// Paket dan komponennya memakai stok produk, tidak bisa punya varian
+	items, err := s.bundleRepo.GetByBundle(ctx, produk.ID)
+	if err != nil {
+		return err
+	}
+	parents, err := s.bundleRepo.GetByComponent(ctx, produk.ID)
+	if err != nil {
+		return err
+	}
+	if len(items) > 0 || len(parents) > 0 {
+		return fmt.Errorf("product %s adalah paket atau komponen paket, tidak bisa punya varian", produk.Name)
+	}
+
+	return s.variantRepo.Create(ctx, variant)
+}
+
+// UpdateVariant - ubah nama, harga dan stok varian. Varian tidak bisa pindah produk
+func (s *ProdukService) UpdateVariant(ctx context.Context, variant *models.ProdukVariant) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	produk, err := s.repo.GetByID(ctx, variant.ProdukID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.findVariant(ctx, variant.ProdukID, variant.ID); err != nil {
+		return err
+	}
+	if err := validateVariant(variant, produk.Unit); err != nil {
+		return err
+	}
+	return s.variantRepo.Update(ctx, variant)
+}
+
+// DeleteVariant - varian diarsipkan, riwayat harga dan detail transaksi lama
+// tetap menunjuk ke varian tersebut
+func (s *ProdukService) DeleteVariant(ctx context.Context, produkID, variantID int) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+
+	if _, err := s.findVariant(ctx, produkID, variantID); err != nil {
+		return err
+	}
+	return s.variantRepo.Delete(ctx, variantID)
+}
+
+func (s *ProdukService) findVariant(ctx context.Context, produkID, variantID int) (*models.ProdukVariant, error) {
+	variant, err := s.variantRepo.GetByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.ProdukID != produkID {
+		return nil, errors.New("varian tidak ditemukan")
+	}
+	return variant, nil
+}
+
+// validateVariant - unit dari produk induk, stok pecahan hanya untuk satuan yang bisa dipecah
+func validateVariant(variant *models.ProdukVariant, unit string) error {
+	if variant.Name == "" {
+		return errors.New("nama varian wajib diisi")
+	}
+	if variant.Price < 0 {
+		return errors.New("harga varian tidak boleh negatif")
+	}
+	if variant.CostPrice < 0 {
+		return errors.New("harga pokok varian tidak boleh negatif")
+	}
+	if variant.Stock < 0 {
+		return errors.New("stok varian tidak boleh negatif")
+	}
+	if !models.Units[unit] && !variant.Stock.IsWhole() {
+		return fmt.Errorf("stok varian dengan unit %s harus bilangan bulat", unit)
+	}
+	return nil
+}
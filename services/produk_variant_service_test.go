@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"kasirApi/models"
+	"kasirApi/repositories/memory"
+)
+
+// newTestProdukService - ProdukService di atas repository memory, tanpa storage gambar
+func newTestProdukService(store *memory.Store) *ProdukService {
+	return NewProdukService(
+		memory.NewProdukRepository(store),
+		memory.NewCategoryRepository(store),
+		memory.NewProdukVariantRepository(store),
+		memory.NewProdukBundleRepository(store),
+		nil,
+		DefaultTimeouts(),
+	)
+}
+
+// createTestVariant - produk "Kaos" dengan satu varian stok stock
+func createTestVariant(t *testing.T, s *ProdukService, stock int) (*models.Produk, *models.ProdukVariant) {
+	t.Helper()
+	ctx := context.Background()
+
+	produk := &models.Produk{Name: "Kaos", Price: 50000}
+	if err := s.Create(ctx, produk); err != nil {
+		t.Fatal(err)
+	}
+	variant := &models.ProdukVariant{ProdukID: produk.ID, Name: "M", Price: 50000, Stock: models.NewQuantity(stock)}
+	if err := s.CreateVariant(ctx, variant); err != nil {
+		t.Fatal(err)
+	}
+	return produk, variant
+}
+
+func TestDeleteVariantKeepsPriceHistory(t *testing.T) {
+	ctx := context.Background()
+	s := newTestProdukService(memory.NewStore())
+	produk, variant := createTestVariant(t, s, 0)
+
+	variant.Price = 55000
+	if err := s.UpdateVariant(ctx, variant); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteVariant(ctx, produk.ID, variant.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := s.GetPriceHistory(ctx, produk.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prices []int
+	for _, h := range history {
+		if h.VariantID == variant.ID {
+			prices = append(prices, h.Price)
+		}
+	}
+	if len(prices) != 2 || prices[0] != 55000 || prices[1] != 50000 {
+		t.Errorf("riwayat harga varian setelah dihapus = %v, mau [55000 50000]", prices)
+	}
+
+	variants, err := s.GetVariants(ctx, produk.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(variants) != 0 {
+		t.Errorf("varian yang dihapus masih muncul: %+v", variants)
+	}
+	if _, err := s.GetVariant(ctx, produk.ID, variant.ID); err == nil {
+		t.Error("GetVariant untuk varian yang dihapus harus error")
+	}
+	if err := s.DeleteVariant(ctx, produk.ID, variant.ID); err == nil {
+		t.Error("hapus varian dua kali harus error")
+	}
+}
+
+func TestDeleteVariantZeroesStockLedger(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	s := newTestProdukService(store)
+	produk, variant := createTestVariant(t, s, 5)
+
+	checkout := NewTransactionService(memory.NewTransactionRepository(store), memory.NewProdukRepository(store), memory.NewUnitOfWork(store), nil, DefaultTimeouts())
+	if _, err := checkout.Checkout(ctx, []models.CheckoutItem{{VariantID: variant.ID, Quantity: models.NewQuantity(2)}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteVariant(ctx, produk.ID, variant.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	movements, err := s.GetStockMovements(ctx, produk.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sum models.Quantity
+	var deleted *models.StockMovement
+	for i, m := range movements {
+		if m.VariantID != variant.ID {
+			continue
+		}
+		sum += m.Quantity
+		if m.Reason == "varian dihapus" {
+			deleted = &movements[i]
+		}
+	}
+	if sum != 0 {
+		t.Errorf("jumlah buku stok varian setelah dihapus = %s, mau 0", sum)
+	}
+	if deleted == nil || deleted.Quantity != models.NewQuantity(-3) || deleted.Type != models.MovementAdjustment {
+		t.Errorf("mutasi varian dihapus = %+v, mau adjustment -3", deleted)
+	}
+}
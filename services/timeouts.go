@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts - batas waktu per jenis operasi. Nilai 0 berarti tanpa batas
+// (tetap ikut context dari request).
+type Timeouts struct {
+	Read     time.Duration // baca katalog: list/detail produk & category
+	Write    time.Duration // create/update/delete katalog
+	Checkout time.Duration // transaksi checkout
+	Report   time.Duration // query laporan penjualan
+}
+
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Read:     3 * time.Second,
+		Write:    5 * time.Second,
+		Checkout: 10 * time.Second,
+		Report:   30 * time.Second,
+	}
+}
+
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -1,16 +1,25 @@
 package services
 
 import (
+	"context"
 	"kasirApi/models"
+	"kasirApi/notifier"
 	"kasirApi/repositories"
+	"log"
+	"time"
 )
 
 type TransactionService struct {
-	repo repositories.TransactionRepository
+	repo       repositories.TransactionRepository
+	produkRepo repositories.ProdukRepository
+	uow        repositories.UnitOfWork
+	// notifier - tujuan alert stok menipis, nil = tidak ada alert
+	notifier notifier.Notifier
+	timeouts Timeouts
 }
 
-func NewTransactionService(repo repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+func NewTransactionService(repo repositories.TransactionRepository, produkRepo repositories.ProdukRepository, uow repositories.UnitOfWork, n notifier.Notifier, timeouts Timeouts) *TransactionService {
+	return &TransactionService{repo: repo, produkRepo: produkRepo, uow: uow, notifier: n, timeouts: timeouts}
 }
 
 // func (s *TransactionService) GetAll(name string) ([]models.Transaction, error) {
@@ -29,15 +38,126 @@ func NewTransactionService(repo repositories.TransactionRepository) *Transaction
 // 	return s.repo.Update(transaction)
 // }
 
-func (s *TransactionService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *TransactionService) Delete(ctx context.Context, id int) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Write)
+	defer cancel()
+	return s.repo.Delete(ctx, id)
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem, useLock bool) (*models.Transaction, error)  {
-	return s.repo.CreateTransaction(items)
+func (s *TransactionService) Checkout(ctx context.Context, items []models.CheckoutItem, useLock bool) (*models.Transaction, error)  {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Checkout)
+	defer cancel()
+
+	var (
+		transaction *models.Transaction
+		alerts      []models.LowStockAlert
+	)
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		transaction, err = s.repo.CreateTransaction(ctx, items)
+		if err != nil {
+			return err
+		}
+		// Dibaca di dalam transaksi supaya stok yang dilihat persis hasil checkout ini
+		alerts, err = s.lowStockAlerts(ctx, transaction)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(alerts)
+	return transaction, nil
+}
+
+// lowStockAlerts - produk yang stoknya baru turun melewati reorder level
+// karena transaksi ini. Yang sudah di bawah batas sebelum checkout tidak
+// dikirim lagi supaya tiap penjualan tidak memicu alert berulang
+func (s *TransactionService) lowStockAlerts(ctx context.Context, transaction *models.Transaction) ([]models.LowStockAlert, error) {
+	if s.notifier == nil {
+		return nil, nil
+	}
+
+	// Stok produk yang berkurang: baris tanpa varian dan bukan paket, plus
+	// komponen paket. Produk yang sama bisa muncul lebih dari sekali
+	sold := map[int]models.Quantity{}
+	var order []int
+	add := func(d models.TransactionDetail) {
+		if _, ok := sold[d.ProductID]; !ok {
+			order = append(order, d.ProductID)
+		}
+		sold[d.ProductID] += d.Quantity
+	}
+	for _, d := range transaction.Details {
+		if len(d.Components) > 0 {
+			for _, c := range d.Components {
+				add(c)
+			}
+		} else if d.VariantID == 0 {
+			add(d)
+		}
+	}
+
+	var alerts []models.LowStockAlert
+	for _, id := range order {
+		produk, err := s.produkRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !produk.IsLowStock() || produk.Stock+sold[id] <= produk.ReorderLevel {
+			continue
+		}
+		alerts = append(alerts, models.LowStockAlert{
+			ProdukID:      produk.ID,
+			Name:          produk.Name,
+			Stock:         produk.Stock,
+			ReorderLevel:  produk.ReorderLevel,
+			Unit:          produk.Unit,
+			TransactionID: transaction.ID,
+			Time:          time.Now(),
+		})
+	}
+	return alerts, nil
 }
 
-func (s *TransactionService) GetSalesReport(startDate, endDate string) (*models.SalesReport, error) {
+// alertTimeout - batas waktu kirim satu alert, webhook yang lambat tidak
+// boleh menahan goroutine selamanya
+const alertTimeout = 10 * time.Second
+
+// notify - kirim alert di background setelah commit, checkout tidak ikut
+// gagal kalau notifier error
+func (s *TransactionService) notify(alerts []models.LowStockAlert) {
+	if len(alerts) == 0 {
+		return
+	}
+	go func() {
+		for _, alert := range alerts {
+			ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+			if err := s.notifier.Notify(ctx, alert); err != nil {
+				log.Printf("alert stok %s gagal dikirim: %v", alert.Name, err)
+			}
+			cancel()
+		}
+	}()
+}
+
+func (s *TransactionService) GetSalesReport(ctx context.Context, startDate, endDate string) (*models.SalesReport, error) {
     // Service acts as a bridge here
-    return s.repo.GetSalesReport(startDate, endDate)
+    ctx, cancel := withTimeout(ctx, s.timeouts.Report)
+    defer cancel()
+    report, err := s.repo.GetSalesReport(ctx, startDate, endDate)
+    if err != nil {
+        return nil, err
+    }
+
+    // Laba kotor dihitung di sini supaya rumusnya sama untuk semua repository
+    report.GrossProfit = report.TotalRevenue - report.TotalCOGS
+    report.GrossMargin = models.GrossMargin(report.TotalRevenue, report.GrossProfit)
+    for i := range report.Produk {
+        p := &report.Produk[i]
+        p.GrossProfit = p.Revenue - p.COGS
+        p.GrossMargin = models.GrossMargin(p.Revenue, p.GrossProfit)
+    }
+
+    return report, nil
 }
\ No newline at end of file
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"kasirApi/models"
+	"kasirApi/repositories/memory"
+)
+
+// recordingNotifier - simpan semua alert, signal dikirim tiap ada alert masuk
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []models.LowStockAlert
+	signal chan struct{}
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert models.LowStockAlert) error {
+	n.mu.Lock()
+	n.alerts = append(n.alerts, alert)
+	n.mu.Unlock()
+	n.signal <- struct{}{}
+	return nil
+}
+
+func TestCheckoutSendsLowStockAlertOnce(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	produkRepo := memory.NewProdukRepository(store)
+	notifier := &recordingNotifier{signal: make(chan struct{}, 10)}
+	service := NewTransactionService(memory.NewTransactionRepository(store), produkRepo, memory.NewUnitOfWork(store), notifier, DefaultTimeouts())
+
+	watched := &models.Produk{Name: "Indomie", Price: 3500, Stock: models.NewQuantity(7), ReorderLevel: models.NewQuantity(5), Unit: models.DefaultUnit}
+	unwatched := &models.Produk{Name: "Teh", Price: 5000, Stock: models.NewQuantity(3), Unit: models.DefaultUnit}
+	for _, p := range []*models.Produk{watched, unwatched} {
+		if err := produkRepo.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkout := func(qty int) {
+		t.Helper()
+		items := []models.CheckoutItem{
+			{ProductID: watched.ID, Quantity: models.NewQuantity(qty)},
+			{ProductID: unwatched.ID, Quantity: models.NewQuantity(1)},
+		}
+		if _, err := service.Checkout(ctx, items, false); err != nil {
+			t.Fatalf("checkout error: %v", err)
+		}
+	}
+
+	// 7 -> 6 masih di atas batas, 6 -> 5 melewati batas, 5 -> 4 sudah di bawah
+	checkout(1)
+	checkout(1)
+	select {
+	case <-notifier.signal:
+	case <-time.After(2 * time.Second):
+		t.Fatal("alert tidak terkirim setelah stok mencapai reorder level")
+	}
+	checkout(1)
+	select {
+	case <-notifier.signal:
+		t.Fatal("alert terkirim lagi untuk produk yang sudah di bawah reorder level")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("jumlah alert = %d, mau 1: %+v", len(notifier.alerts), notifier.alerts)
+	}
+	alert := notifier.alerts[0]
+	if alert.ProdukID != watched.ID || alert.Stock != models.NewQuantity(5) || alert.ReorderLevel != models.NewQuantity(5) {
+		t.Errorf("alert = %+v, mau produk %d stok 5 batas 5", alert, watched.ID)
+	}
+	if alert.TransactionID != 2 {
+		t.Errorf("transaction_id = %d, mau 2", alert.TransactionID)
+	}
+}
+
+func TestCheckoutRejectsDeletedVariant(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	produkService := newTestProdukService(store)
+	service := NewTransactionService(memory.NewTransactionRepository(store), memory.NewProdukRepository(store), memory.NewUnitOfWork(store), nil, DefaultTimeouts())
+	produk, variant := createTestVariant(t, produkService, 5)
+
+	sold, err := service.Checkout(ctx, []models.CheckoutItem{{VariantID: variant.ID, Quantity: models.NewQuantity(1)}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := produkService.DeleteVariant(ctx, produk.ID, variant.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Struk lama tetap menunjuk ke variannya
+	if d := sold.Details[0]; d.VariantID != variant.ID || d.VariantName != "M" {
+		t.Errorf("detail transaksi = %+v, mau tetap varian %d (M)", d, variant.ID)
+	}
+
+	if _, err := service.Checkout(ctx, []models.CheckoutItem{{VariantID: variant.ID, Quantity: models.NewQuantity(1)}}, false); err == nil {
+		t.Error("checkout varian yang sudah dihapus harus ditolak")
+	}
+	// Tanpa varian aktif produk dijual langsung, tidak lagi wajib variant_id
+	if err := produkService.AdjustStock(ctx, &models.StockMovement{ProdukID: produk.ID, Type: models.MovementReceiving, Quantity: models.NewQuantity(3), Reason: "barang datang", User: "gudang"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Checkout(ctx, []models.CheckoutItem{{ProductID: produk.ID, Quantity: models.NewQuantity(1)}}, false); err != nil {
+		t.Errorf("checkout produk tanpa varian aktif: %v", err)
+	}
+}
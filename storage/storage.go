@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound - object dengan key tersebut tidak ada
+var ErrNotFound = errors.New("file tidak ditemukan")
+
+// ErrInvalidKey - key kosong atau mencoba keluar dari folder penyimpanan
+var ErrInvalidKey = errors.New("key file tidak valid")
+
+// Info - metadata object yang disimpan
+type Info struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// Storage - tempat menyimpan file upload (gambar produk). Key berbentuk path
+// relatif dengan pemisah "/", misal produk/1/ab12cd.jpg. Implementasi lain
+// (S3, GCS, ...) cukup memenuhi interface ini.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get - isi file beserta metadata, pemanggil wajib Close
+	Get(ctx context.Context, key string) (io.ReadCloser, *Info, error)
+	// Delete - hapus file, tidak error kalau file memang tidak ada
+	Delete(ctx context.Context, key string) error
+	// URL - alamat publik untuk key, dipakai sebagai image_url di JSON
+	URL(key string) string
+}